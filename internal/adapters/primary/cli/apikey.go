@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newAPIKeyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apikey",
+		Short: "Manage API keys (requires the api_keys migration and API_KEY_PEPPER)",
+	}
+	cmd.AddCommand(newAPIKeyCreateCommand(), newAPIKeyListCommand(), newAPIKeyRevokeCommand())
+	return cmd
+}
+
+func newAPIKeyCreateCommand() *cobra.Command {
+	var (
+		scopes []string
+		ttl    time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Issue a key and print it; the key can't be shown again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if ttl < 0 {
+				return fmt.Errorf("ttl must not be negative, got %s", ttl)
+			}
+
+			c, err := loadContainer()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			manager, err := c.APIKeys()
+			if err != nil {
+				return err
+			}
+
+			plaintext, key, err := manager.Issue(cmd.Context(), args[0], scopes, ttl)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "id=%s name=%s scopes=%s expires=%s\n", key.ID, key.Name, strings.Join(key.Scopes, ","), formatTime(key.ExpiresAt))
+			fmt.Fprintln(out, plaintext)
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&scopes, "scope", nil, "scope granted to the key; repeat or comma-separate for several")
+	cmd.Flags().DurationVar(&ttl, "ttl", 0, "lifetime of the key, e.g. 720h; 0 never expires")
+	return cmd
+}
+
+func newAPIKeyListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List keys with their status and last use",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadContainer()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			manager, err := c.APIKeys()
+			if err != nil {
+				return err
+			}
+
+			keys, err := manager.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tSTATUS\tCREATED\tEXPIRES\tLAST USED")
+			for _, key := range keys {
+				status := "active"
+				switch {
+				case key.RevokedAt != nil:
+					status = "revoked"
+				case !key.Active(now):
+					status = "expired"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					key.ID, key.Name, key.Prefix, strings.Join(key.Scopes, ","), status,
+					formatTime(&key.CreatedAt), formatTime(key.ExpiresAt), formatTime(key.LastUsedAt))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newAPIKeyRevokeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Revoke a key; running servers stop accepting it within API_KEY_CACHE_TTL",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadContainer()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			manager, err := c.APIKeys()
+			if err != nil {
+				return err
+			}
+
+			if err := manager.Revoke(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "revoked %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
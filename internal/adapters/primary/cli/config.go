@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"encoding/json"
+	"go-chi-boilerplate/internal/config"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "config",
+		Short: "Print the effective configuration with secrets redacted and validate it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := config.LoadAppConfigs()
+
+			raw, err := json.Marshal(cfg)
+			if err != nil {
+				return err
+			}
+			var doc map[string]any
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				return err
+			}
+			redact(doc)
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(doc); err != nil {
+				return err
+			}
+
+			return cfg.Validate()
+		},
+	}
+}
+
+// redact masks non-empty values whose field name looks like a credential
+func redact(v map[string]any) {
+	for k, val := range v {
+		switch val := val.(type) {
+		case map[string]any:
+			redact(val)
+		case string:
+			name := strings.ToLower(k)
+			if val != "" && (strings.Contains(name, "password") || strings.Contains(name, "secret") || strings.Contains(name, "token") || strings.Contains(name, "pepper")) {
+				v[k] = "******"
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"go-chi-boilerplate/internal/config"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func newHealthcheckCommand() *cobra.Command {
+	var (
+		probe     string
+		timeout   time.Duration
+		checkGRPC bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "healthcheck",
+		Short: "Probe the local server; exits non-zero when it is unhealthy",
+		Long: "Calls /system/liveness or /system/readiness on PORT and, with --grpc, the\n" +
+			"grpc.health.v1 service on GRPC_PORT. Meant for container HEALTHCHECKs on images\n" +
+			"without curl or wget.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if probe != "liveness" && probe != "readiness" {
+				return fmt.Errorf("probe must be liveness or readiness, got %q", probe)
+			}
+
+			cfg := config.LoadAppConfigs()
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			if err := checkHTTP(ctx, cfg.Server.Port, probe); err != nil {
+				return err
+			}
+			if checkGRPC {
+				if err := checkGRPCHealth(ctx, cfg.GRPC.Port); err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "ok")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&probe, "probe", "liveness", "HTTP probe to call: liveness or readiness")
+	cmd.Flags().DurationVar(&timeout, "timeout", 3*time.Second, "overall timeout for all checks")
+	cmd.Flags().BoolVar(&checkGRPC, "grpc", false, "also check the gRPC health service")
+	return cmd
+}
+
+func checkHTTP(ctx context.Context, port, probe string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://localhost%s/system/%s", port, probe), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s probe failed: %w", probe, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s probe returned %s", probe, resp.Status)
+	}
+	return nil
+}
+
+func checkGRPCHealth(ctx context.Context, port string) error {
+	conn, err := grpc.NewClient("localhost"+port, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return fmt.Errorf("grpc health check failed: %w", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("grpc health status is %s", resp.GetStatus())
+	}
+	return nil
+}
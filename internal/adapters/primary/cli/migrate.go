@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand() *cobra.Command {
+	var path string
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage database migrations",
+	}
+	cmd.PersistentFlags().StringVar(&path, "path", "./migrations", "directory containing the migration files")
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply all pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				c, err := loadContainer()
+				if err != nil {
+					return err
+				}
+				defer c.Close()
+
+				db, err := c.DB()
+				if err != nil {
+					return err
+				}
+				return postgresql.RunMigrations(db, path, c.Logger)
+			},
+		},
+		&cobra.Command{
+			Use:   "down [steps]",
+			Short: "Roll back the given number of migrations (default 1)",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				steps := 1
+				if len(args) == 1 {
+					n, err := strconv.Atoi(args[0])
+					if err != nil || n < 1 {
+						return fmt.Errorf("steps must be a positive integer, got %q", args[0])
+					}
+					steps = n
+				}
+
+				c, err := loadContainer()
+				if err != nil {
+					return err
+				}
+				defer c.Close()
+
+				db, err := c.DB()
+				if err != nil {
+					return err
+				}
+				return postgresql.RollbackMigrations(db, path, steps, c.Logger)
+			},
+		},
+		&cobra.Command{
+			Use:   "version",
+			Short: "Print the current schema version",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				c, err := loadContainer()
+				if err != nil {
+					return err
+				}
+				defer c.Close()
+
+				db, err := c.DB()
+				if err != nil {
+					return err
+				}
+
+				version, dirty, err := postgresql.MigrationVersion(db, path, c.Logger)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "version=%d dirty=%t\n", version, dirty)
+				return nil
+			},
+		},
+	)
+	return cmd
+}
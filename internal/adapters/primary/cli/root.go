@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+	"go-chi-boilerplate/internal/app"
+	"go-chi-boilerplate/internal/config"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Execute runs the command line interface and exits non-zero on failure
+func Execute() {
+	if err := NewRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// NewRootCommand builds the command tree; running the binary without a subcommand serves
+func NewRootCommand() *cobra.Command {
+	serve := newServeCommand()
+
+	root := &cobra.Command{
+		Use:          "app",
+		Short:        "go-chi-boilerplate service",
+		SilenceUsage: true,
+		RunE:         serve.RunE,
+	}
+
+	root.AddCommand(
+		serve,
+		newMigrateCommand(),
+		newConfigCommand(),
+		newVersionCommand(),
+		newHealthcheckCommand(),
+		newAPIKeyCommand(),
+	)
+	return root
+}
+
+// loadContainer loads and validates the configs and builds the container
+func loadContainer() (*app.Container, error) {
+	cfg, err := config.GetAppConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load application configs: %w", err)
+	}
+	return app.New(cfg), nil
+}
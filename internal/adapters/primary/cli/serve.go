@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/adapters/primary/grpc/gateway"
+	"go-chi-boilerplate/internal/adapters/primary/grpc/interceptors"
+	grpcserver "go-chi-boilerplate/internal/adapters/primary/grpc/server"
+	custom "go-chi-boilerplate/internal/adapters/primary/http/middleware"
+	"go-chi-boilerplate/internal/adapters/primary/http/routes"
+	"go-chi-boilerplate/internal/adapters/primary/http/server"
+	"go-chi-boilerplate/internal/adapters/primary/http/session"
+	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql/outbox"
+	"go-chi-boilerplate/internal/core/domain"
+	"go-chi-boilerplate/internal/core/ports"
+	"go-chi-boilerplate/internal/meta"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP and gRPC servers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serve()
+		},
+	}
+}
+
+func serve() error {
+	c, err := loadContainer()
+	if err != nil {
+		meta.Fatal(meta.NewLogger("error"), "failed to load application configs", "error", err)
+	}
+	defer c.Close()
+
+	cfg := c.Config
+	logger := c.Logger
+
+	// Init metrics
+	meta.InitMetrics()
+
+	// Init tracer
+	tp, err := meta.InitTracer(cfg.Server.ServiceName, cfg.Server.OTLPEndpoint, logger)
+	if err != nil {
+		meta.Fatal(logger, "failed to initialize tracer", "error", err)
+	}
+	defer shutdownTracer(tp, logger)
+
+	// Cancelled on SIGINT/SIGTERM to trigger graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start continuous profiler (optional)
+	var wg sync.WaitGroup
+	if cfg.Profiling.Enabled {
+		profiler, err := meta.NewProfiler(cfg.Profiling, cfg.Server, logger)
+		if err != nil {
+			meta.Fatal(logger, "failed to initialize profiler", "error", err)
+		}
+		meta.InitProfilingMetrics()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			profiler.Run(ctx)
+		}()
+	}
+
+	// Connect to PostgreSQL
+	db, err := c.DB()
+	if err != nil {
+		meta.Fatal(logger, "failed to connect to database", "error", err)
+	}
+
+	// Initialize PostgreSQL metrics
+	meta.InitDBMetrics(db)
+
+	// Connect to Redis and build the cache (optional)
+	cache, err := c.Cache(ctx)
+	if err != nil {
+		meta.Fatal(logger, "failed to connect to redis", "error", err)
+	}
+	if cache != nil {
+		meta.InitCacheMetrics()
+	}
+
+	// Load email templates
+	renderer, err := c.EmailRenderer()
+	if err != nil {
+		meta.Fatal(logger, "failed to load email templates", "error", err)
+	}
+
+	// Init mailer
+	mailer, err := c.Mailer()
+	if err != nil {
+		meta.Fatal(logger, "failed to initialize mailer", "error", err)
+	}
+
+	// Init bearer token and API key verification (optional) and authorization
+	verifier, err := c.TokenVerifier()
+	if err != nil {
+		meta.Fatal(logger, "failed to initialize token verifier", "error", err)
+	}
+	var apiKeys ports.TokenVerifier
+	if cfg.APIKeys.Enabled {
+		manager, err := c.APIKeys()
+		if err != nil {
+			meta.Fatal(logger, "failed to initialize api keys", "error", err)
+		}
+		apiKeys = manager
+	}
+	// Routes are authorized by the policy only when AUTH_POLICY_FILE defines one;
+	// without it no role would grant anything
+	var policy ports.PolicyEngine
+	if cfg.Auth.PolicyFile != "" {
+		engine, err := c.PolicyEngine()
+		if err != nil {
+			meta.Fatal(logger, "failed to initialize authorization policy", "error", err)
+		}
+		policy = engine
+	}
+	meta.InitAuthMetrics()
+
+	// Init browser sign-in with OIDC (optional); sessions live in sealed cookies unless
+	// SESSION_STORE selects a server-side store
+	var cookies *session.Cookies
+	var sessions session.Store
+	if cfg.OIDC.Enabled() {
+		opts := session.CookieOptions{
+			Secure:   cfg.Session.CookieSecure,
+			SameSite: session.ParseSameSite(cfg.Session.CookieSameSite),
+			Domain:   cfg.Session.CookieDomain,
+		}
+		// The login state must survive the redirect back from the provider
+		cookies, err = session.NewCookies(cfg.Session.CookieSecret, session.CookieOptions{Secure: opts.Secure, SameSite: http.SameSiteLaxMode, Domain: opts.Domain})
+		if err != nil {
+			meta.Fatal(logger, "failed to initialize session cookies", "error", err)
+		}
+
+		store, err := c.SessionStore(ctx)
+		if err != nil {
+			meta.Fatal(logger, "failed to initialize session store", "error", err)
+		}
+		if store != nil {
+			sessions = session.NewManager(store, session.ManagerOptions{
+				CookieName:      cfg.Session.CookieName,
+				Cookie:          opts,
+				AbsoluteTimeout: cfg.Session.TTL,
+				IdleTimeout:     cfg.Session.IdleTimeout,
+			}, logger)
+		} else {
+			sealed, err := session.NewCookies(cfg.Session.CookieSecret, opts)
+			if err != nil {
+				meta.Fatal(logger, "failed to initialize session cookies", "error", err)
+			}
+			sessions = session.NewCookieStore(sealed, cfg.Session.CookieName, cfg.Session.TTL)
+		}
+	}
+
+	// Init rate limiting of /api (optional)
+	var rateLimit, authRateLimit func(http.Handler) http.Handler
+	if cfg.RateLimit.Enabled {
+		limiter, err := c.RateLimiter()
+		if err != nil {
+			meta.Fatal(logger, "failed to initialize rate limiter", "error", err)
+		}
+		meta.InitRateLimitMetrics()
+
+		keys := make([]custom.RateLimitKey, 0, len(cfg.RateLimit.KeyBy))
+		for _, name := range cfg.RateLimit.KeyBy {
+			keys = append(keys, custom.RateLimitKeys[name])
+		}
+		rateLimit = custom.RateLimit(limiter, domain.RateLimit{
+			Algorithm: domain.RateLimitAlgorithm(cfg.RateLimit.Algorithm),
+			Limit:     cfg.RateLimit.Limit,
+			Window:    cfg.RateLimit.Window,
+			Burst:     cfg.RateLimit.Burst,
+		}, custom.KeyBy(keys...))
+		authRateLimit = custom.LimitFailedAuth(limiter, domain.RateLimit{
+			Algorithm: domain.SlidingWindow,
+			Limit:     cfg.RateLimit.AuthFailures,
+			Window:    cfg.RateLimit.Window,
+		}, custom.KeyByIP)
+	}
+
+	// Start outbox dispatcher (requires the outbox migration, see `migrate up`)
+	if cfg.Outbox.Enabled {
+		meta.InitOutboxMetrics()
+		dispatcher := outbox.NewDispatcher(db, cfg.Outbox, logger)
+		dispatcher.Register(outbox.TopicEmail, outbox.EmailHandler(mailer))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dispatcher.Run(ctx)
+		}()
+	}
+
+	// Start gRPC server
+	if cfg.GRPC.Enabled {
+		meta.InitGRPCMetrics()
+		grpcServer := grpcserver.New(cfg.GRPC, logger, c.Health(), interceptors.ServerOptions(logger)...)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			grpcServer.Run(ctx)
+		}()
+	}
+
+	// Expose gRPC services over REST/JSON; add the generated Register<Service>Handler functions here
+	var gatewayServices []gateway.RegisterFunc
+
+	var gatewayMux http.Handler
+	var gatewaySpecs [][]byte
+	if cfg.GRPC.Enabled && cfg.GRPC.GatewayEnabled {
+		gw, err := gateway.New(ctx, cfg.GRPC.Port, logger, gatewayServices...)
+		if err != nil {
+			meta.Fatal(logger, "failed to initialize grpc gateway", "error", err)
+		}
+		defer gw.Close()
+		gatewayMux = gw.Mux
+
+		gatewaySpecs, err = gateway.LoadOpenAPISpecs(cfg.GRPC.GatewayOpenAPIDir)
+		if err != nil {
+			logger.Warn("failed to load grpc gateway OpenAPI specs", "dir", cfg.GRPC.GatewayOpenAPIDir, "error", err)
+		}
+	}
+
+	// Start HTTP server
+	server.New(cfg.Server, cfg.System, logger, routes.Dependencies{
+		DB:            db,
+		Health:        c.Health(),
+		Cache:         cache,
+		EmailRenderer: renderer,
+		Auth:          verifier,
+		APIKeys:       apiKeys,
+		OIDC:          c.OIDC(),
+		Cookies:       cookies,
+		Sessions:      sessions,
+		Policy:        policy,
+		RateLimit:     rateLimit,
+		AuthRateLimit: authRateLimit,
+		Gateway:       gatewayMux,
+		GatewaySpecs:  gatewaySpecs,
+	}).Run(ctx)
+
+	wg.Wait()
+	return nil
+}
+
+func shutdownTracer(tp interface{ Shutdown(context.Context) error }, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := tp.Shutdown(ctx); err != nil {
+		logger.Error("failed to shutdown tracer", "error", err)
+	}
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-chi-boilerplate/internal/meta"
+
+	"github.com/spf13/cobra"
+)
+
+func newVersionCommand() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the build metadata",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bi := meta.Build()
+
+			if asJSON {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(bi)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "version:    %s\ncommit:     %s\nbuild date: %s\ngo version: %s\ndirty:      %t\n",
+				bi.Version, bi.Commit, bi.BuildDate, bi.GoVersion, bi.Dirty)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print as JSON")
+	return cmd
+}
@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"go-chi-boilerplate/internal/meta"
+	"log/slog"
+	"net"
+	"net/textproto"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// RegisterFunc matches the Register<Service>Handler functions generated by protoc-gen-grpc-gateway
+type RegisterFunc func(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error
+
+// Gateway transcodes REST/JSON requests into calls on the local gRPC server
+type Gateway struct {
+	Mux  *runtime.ServeMux
+	conn *grpc.ClientConn
+}
+
+// New connects to the gRPC server listening on grpcPort and registers the given services.
+// The returned mux matches full request paths, so HTTP rules in the protos should include
+// the /api prefix the gateway is mounted under.
+func New(ctx context.Context, grpcPort string, logger *slog.Logger, registrars ...RegisterFunc) (*Gateway, error) {
+	_, port, err := net.SplitHostPort(grpcPort)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grpc port %q: %w", grpcPort, err)
+	}
+
+	conn, err := grpc.NewClient(net.JoinHostPort("localhost", port),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect gateway to grpc server: %w", err)
+	}
+
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{UseProtoNames: true},
+		}),
+	)
+
+	for _, register := range registrars {
+		if err := register(ctx, mux, conn); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to register gateway handler: %w", err)
+		}
+	}
+
+	logger.Info("grpc gateway configured", "grpc_port", grpcPort, "services", len(registrars))
+
+	return &Gateway{Mux: mux, conn: conn}, nil
+}
+
+// Close closes the connection to the gRPC server
+func (g *Gateway) Close() error {
+	return g.conn.Close()
+}
+
+// incomingHeaderMatcher forwards the request ID header as metadata on top of the default rules
+func incomingHeaderMatcher(key string) (string, bool) {
+	if textproto.CanonicalMIMEHeaderKey(key) == textproto.CanonicalMIMEHeaderKey(meta.RequestIDHeader) {
+		return strings.ToLower(meta.RequestIDHeader), true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// outgoingHeaderMatcher returns the request ID metadata as a plain header and
+// prefixes all other metadata with Grpc-Metadata-, as the gateway does by default
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if textproto.CanonicalMIMEHeaderKey(key) == textproto.CanonicalMIMEHeaderKey(meta.RequestIDHeader) {
+		return meta.RequestIDHeader, true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
@@ -0,0 +1,101 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"log/slog"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// UnaryErrors converts application errors into gRPC statuses
+func UnaryErrors(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			err = toStatus(ctx, logger, info.FullMethod, err)
+		}
+		return resp, err
+	}
+}
+
+// StreamErrors converts application errors into gRPC statuses
+func StreamErrors(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err != nil {
+			err = toStatus(ss.Context(), logger, info.FullMethod, err)
+		}
+		return err
+	}
+}
+
+// Code returns the gRPC code for an error kind
+func Code(kind apperrors.Kind) codes.Code {
+	switch kind {
+	case apperrors.KindNotFound:
+		return codes.NotFound
+	case apperrors.KindBadRequest, apperrors.KindValidation:
+		return codes.InvalidArgument
+	case apperrors.KindConflict:
+		return codes.AlreadyExists
+	case apperrors.KindUnauthorized:
+		return codes.Unauthenticated
+	case apperrors.KindForbidden:
+		return codes.PermissionDenied
+	case apperrors.KindRateLimited:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
+
+// toStatus keeps errors that already carry a status, maps application errors with the
+// code in ErrorInfo and hides anything else behind codes.Internal. Internal causes
+// are only logged.
+func toStatus(ctx context.Context, logger *slog.Logger, fullMethod string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	appErr := apperrors.From(err)
+	code := Code(appErr.Kind)
+
+	level := slog.LevelDebug
+	if code == codes.Internal {
+		level = slog.LevelError
+	}
+	logger.Log(ctx, level, "grpc request failed",
+		"method", fullMethod,
+		"code", appErr.Code,
+		"error", err,
+	)
+
+	st := status.New(code, appErr.Message)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: appErr.Code}}
+	if len(appErr.Fields) > 0 {
+		br := &errdetails.BadRequest{}
+		for field, msg := range appErr.Fields {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: field, Description: msg})
+		}
+		details = append(details, br)
+	}
+	if appErr.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(appErr.RetryAfter)})
+	}
+
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -0,0 +1,65 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// ServerOptions returns the interceptor chain mirroring the HTTP middleware stack:
+// tracing, profile labels, request ID, logging, metrics, error mapping and panic recovery (innermost,
+// so the others observe the status codes they produce)
+func ServerOptions(logger *slog.Logger) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			UnaryProfileLabels(),
+			UnaryRequestID(),
+			UnaryLogging(logger),
+			UnaryMetrics(),
+			UnaryErrors(logger),
+			UnaryRecovery(logger),
+		),
+		grpc.ChainStreamInterceptor(
+			StreamProfileLabels(),
+			StreamRequestID(),
+			StreamLogging(logger),
+			StreamMetrics(),
+			StreamErrors(logger),
+			StreamRecovery(logger),
+		),
+	}
+}
+
+// splitMethod turns "/package.Service/Method" into its service and method names
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
+
+// wrappedStream overrides the context of a server stream
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return "bidi_stream"
+	case info.IsClientStream:
+		return "client_stream"
+	default:
+		return "server_stream"
+	}
+}
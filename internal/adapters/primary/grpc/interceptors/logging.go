@@ -0,0 +1,67 @@
+package interceptors
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/meta"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryLogging logs each RPC using the provided slog.Logger
+func UnaryLogging(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logRPC(ctx, logger, info.FullMethod, "unary", start, err)
+		return resp, err
+	}
+}
+
+// StreamLogging logs each streaming RPC using the provided slog.Logger
+func StreamLogging(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logRPC(ss.Context(), logger, info.FullMethod, streamType(info), start, err)
+		return err
+	}
+}
+
+func logRPC(ctx context.Context, logger *slog.Logger, fullMethod, rpcType string, start time.Time, err error) {
+	// Skip health probes like the HTTP logger skips /system/health
+	if fullMethod == "/grpc.health.v1.Health/Check" {
+		return
+	}
+
+	code := status.Code(err)
+
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+
+	traceID := ""
+	if sc := trace.SpanFromContext(ctx).SpanContext(); sc.IsValid() {
+		traceID = sc.TraceID().String()
+	}
+
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+	}
+
+	logger.Log(ctx, level, "grpc_request",
+		"method", fullMethod,
+		"type", rpcType,
+		"code", code.String(),
+		"duration_ms", time.Since(start).Milliseconds(),
+		"remote_addr", remoteAddr,
+		"trace_id", traceID,
+		"request_id", meta.RequestIDFromContext(ctx),
+	)
+}
@@ -0,0 +1,37 @@
+package interceptors
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/meta"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryMetrics records grpc_server_handled_total and grpc_server_handling_seconds
+func UnaryMetrics() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observe(info.FullMethod, "unary", start, err)
+		return resp, err
+	}
+}
+
+// StreamMetrics records grpc_server_handled_total and grpc_server_handling_seconds
+func StreamMetrics() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observe(info.FullMethod, streamType(info), start, err)
+		return err
+	}
+}
+
+func observe(fullMethod, rpcType string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+
+	meta.GRPCServerHandledTotal.WithLabelValues(rpcType, service, method, status.Code(err).String()).Inc()
+	meta.GRPCServerHandlingSeconds.WithLabelValues(rpcType, service, method).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,29 @@
+package interceptors
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/meta"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryProfileLabels labels the CPU samples of the call with its span ID so profiles
+// can be opened from the trace
+func UnaryProfileLabels() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		meta.WithSpanProfileLabels(ctx, func(ctx context.Context) {
+			resp, err = handler(ctx, req)
+		})
+		return resp, err
+	}
+}
+
+// StreamProfileLabels labels the CPU samples of the stream with its span ID
+func StreamProfileLabels() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		meta.WithSpanProfileLabels(ss.Context(), func(ctx context.Context) {
+			err = handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+		})
+		return err
+	}
+}
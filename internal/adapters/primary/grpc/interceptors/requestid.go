@@ -0,0 +1,42 @@
+package interceptors
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/meta"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var requestIDKey = strings.ToLower(meta.RequestIDHeader)
+
+// UnaryRequestID propagates the x-request-id metadata, generating one when absent
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withRequestID(ctx), req)
+	}
+}
+
+// StreamRequestID propagates the x-request-id metadata, generating one when absent
+func StreamRequestID() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+	}
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(requestIDKey); len(v) > 0 {
+			id = v[0]
+		}
+	}
+	if id == "" {
+		id = meta.NewRequestID()
+	}
+
+	// Echo the ID back to the caller in the response headers
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	return meta.WithRequestID(ctx, id)
+}
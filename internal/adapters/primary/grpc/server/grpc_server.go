@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/core/health"
+	"log/slog"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type Server struct {
+	cfg     *config.GRPCConfigs
+	logger  *slog.Logger
+	checker *health.Checker
+	health  *grpchealth.Server
+	grpc    *grpc.Server
+}
+
+// New creates a gRPC Server exposing grpc.health.v1 backed by the readiness checks
+// and, when enabled, server reflection
+func New(cfg *config.GRPCConfigs, logger *slog.Logger, checker *health.Checker, opts ...grpc.ServerOption) *Server {
+	s := grpc.NewServer(opts...)
+
+	hs := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
+
+	if cfg.Reflection {
+		reflection.Register(s)
+	}
+
+	return &Server{
+		cfg:     cfg,
+		logger:  logger,
+		checker: checker,
+		health:  hs,
+		grpc:    s,
+	}
+}
+
+// Register adds services to the server; call it before Run
+func (s *Server) Register(register func(grpc.ServiceRegistrar)) {
+	register(s.grpc)
+}
+
+// Run serves gRPC until ctx is done, then stops gracefully
+func (s *Server) Run(ctx context.Context) {
+	lis, err := net.Listen("tcp", s.cfg.Port)
+	if err != nil {
+		s.logger.Error("error starting grpc server", "error", err)
+		return
+	}
+
+	go s.watchHealth(ctx)
+
+	go func() {
+		s.logger.Info("grpc server starting", "port", s.cfg.Port)
+		if err := s.grpc.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			s.logger.Error("error starting grpc server", "error", err)
+		}
+	}()
+
+	<-ctx.Done()
+	s.logger.Info("shutting down grpc server...")
+
+	// Tell health-checking clients to stop routing here before draining
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		s.logger.Info("grpc server stopped gracefully")
+	case <-time.After(5 * time.Second):
+		s.grpc.Stop()
+		s.logger.Warn("grpc server forced to stop after timeout")
+	}
+}
+
+// watchHealth mirrors the readiness checks into the health service; the empty
+// service name reports overall server health
+func (s *Server) watchHealth(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		report := s.checker.Run(ctx)
+		if !report.Ready() {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.health.SetServingStatus("", status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package endpoint
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/adapters/primary/http/openapi"
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/adapters/primary/http/request"
+	"go-chi-boilerplate/internal/adapters/primary/http/response"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Func handles a bound and validated request; returned errors are written as problems
+type Func[Req, Resp any] func(ctx context.Context, req *Req) (Resp, error)
+
+// Empty is the request or response type of endpoints without parameters or body;
+// an Empty response is sent as 204 No Content
+type Empty struct{}
+
+// Operation documents an endpoint
+type Operation struct {
+	ID          string
+	Summary     string
+	Description string
+	Tags        []string
+	// Status is the success status code; it defaults to 200, or 204 for Empty responses
+	Status     int
+	Deprecated bool
+	// Security lists the security schemes that accept this endpoint, e.g. {"bearer": nil}
+	Security []map[string][]string
+}
+
+// Group registers typed endpoints on a chi router and documents them under a path prefix
+type Group struct {
+	router chi.Router
+	doc    *openapi.Document
+	prefix string
+
+	// Tags are added to every operation of the group
+	Tags []string
+	// Deprecated marks every operation of the group as deprecated
+	Deprecated bool
+}
+
+// NewGroup creates a Group; prefix is the path r is mounted at, e.g. /api/v2
+func NewGroup(r chi.Router, doc *openapi.Document, prefix string) *Group {
+	return &Group{router: r, doc: doc, prefix: prefix}
+}
+
+// With returns a copy of the group whose endpoints run behind the given middlewares
+func (g *Group) With(middlewares ...func(http.Handler) http.Handler) *Group {
+	c := *g
+	c.router = g.router.With(middlewares...)
+	return &c
+}
+
+// Router returns the underlying router for routes that are not typed endpoints
+func (g *Group) Router() chi.Router {
+	return g.router
+}
+
+// Handle registers fn for method and pattern and adds it to the OpenAPI document
+func Handle[Req, Resp any](g *Group, method, pattern string, fn Func[Req, Resp], op Operation) {
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+		if isEmpty[Resp]() {
+			status = http.StatusNoContent
+		}
+	}
+
+	g.router.Method(method, pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		if err := request.Bind(r, &req); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		resp, err := fn(r.Context(), &req)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		if status == http.StatusNoContent {
+			response.NoContent(w)
+			return
+		}
+		response.JSON(w, r, status, resp)
+	}))
+
+	g.document(method, pattern, status, reflect.TypeFor[Req](), reflect.TypeFor[Resp](), op)
+}
+
+// Raw registers a hand-written handler and documents it with the Req and Resp types,
+// for endpoints that need control over the response such as non-JSON bodies
+func Raw[Req, Resp any](g *Group, method, pattern string, h http.HandlerFunc, op Operation) {
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	g.router.Method(method, pattern, h)
+	g.document(method, pattern, status, reflect.TypeFor[Req](), reflect.TypeFor[Resp](), op)
+}
+
+// Get registers a GET endpoint
+func Get[Req, Resp any](g *Group, pattern string, fn Func[Req, Resp], op Operation) {
+	Handle(g, http.MethodGet, pattern, fn, op)
+}
+
+// Post registers a POST endpoint
+func Post[Req, Resp any](g *Group, pattern string, fn Func[Req, Resp], op Operation) {
+	Handle(g, http.MethodPost, pattern, fn, op)
+}
+
+// Put registers a PUT endpoint
+func Put[Req, Resp any](g *Group, pattern string, fn Func[Req, Resp], op Operation) {
+	Handle(g, http.MethodPut, pattern, fn, op)
+}
+
+// Patch registers a PATCH endpoint
+func Patch[Req, Resp any](g *Group, pattern string, fn Func[Req, Resp], op Operation) {
+	Handle(g, http.MethodPatch, pattern, fn, op)
+}
+
+// Delete registers a DELETE endpoint
+func Delete[Req, Resp any](g *Group, pattern string, fn Func[Req, Resp], op Operation) {
+	Handle(g, http.MethodDelete, pattern, fn, op)
+}
+
+func (g *Group) document(method, pattern string, status int, reqType, respType reflect.Type, op Operation) {
+	if g.doc == nil {
+		return
+	}
+
+	problemSchema := g.doc.Schema(reflect.TypeFor[problem.Problem]())
+	problemContent := map[string]*openapi.MediaType{problem.ContentType: {Schema: problemSchema}}
+
+	o := &openapi.Operation{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        append(append([]string{}, g.Tags...), op.Tags...),
+		Deprecated:  op.Deprecated || g.Deprecated,
+		Parameters:  g.doc.Parameters(reqType),
+		Security:    op.Security,
+		Responses: map[string]*openapi.Response{
+			"default": {Description: "Error", Content: problemContent},
+		},
+	}
+
+	if method != http.MethodGet && method != http.MethodDelete && !isEmptyType(reqType) && openapi.HasBody(reqType) {
+		o.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]*openapi.MediaType{"application/json": {Schema: g.doc.Schema(reqType)}},
+		}
+	}
+
+	success := &openapi.Response{Description: http.StatusText(status)}
+	if status != http.StatusNoContent {
+		success.Content = map[string]*openapi.MediaType{"application/json": {Schema: g.doc.Schema(respType)}}
+	}
+	o.Responses[strconv.Itoa(status)] = success
+
+	g.doc.AddOperation(method, g.prefix+pattern, o)
+}
+
+func isEmpty[T any]() bool {
+	return isEmptyType(reflect.TypeFor[T]())
+}
+
+func isEmptyType(t reflect.Type) bool {
+	return t == reflect.TypeFor[Empty]() || (t.Kind() == reflect.Struct && t.NumField() == 0)
+}
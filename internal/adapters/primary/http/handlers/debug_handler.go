@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"go-chi-boilerplate/internal/adapters/primary/http/endpoint"
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/adapters/primary/http/request"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/meta"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	rpprof "runtime/pprof"
+	"runtime/trace"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GCStatsResponse is the body of /system/debug/gc
+type GCStatsResponse struct {
+	NumGC         int64    `json:"num_gc"`
+	LastGC        string   `json:"last_gc,omitempty"`
+	PauseTotal    string   `json:"pause_total"`
+	RecentPauses  []string `json:"recent_pauses" doc:"Most recent GC pauses, newest first"`
+	HeapAlloc     uint64   `json:"heap_alloc_bytes"`
+	HeapInuse     uint64   `json:"heap_inuse_bytes"`
+	HeapObjects   uint64   `json:"heap_objects"`
+	NextGC        uint64   `json:"next_gc_bytes"`
+	Sys           uint64   `json:"sys_bytes"`
+	Goroutines    int      `json:"goroutines"`
+	GCCPUFraction float64  `json:"gc_cpu_fraction"`
+	GOGC          int      `json:"gogc" doc:"Current GOGC percentage"`
+	GOMemoryLimit int64    `json:"go_memory_limit_bytes"`
+	GOMAXPROCS    int      `json:"gomaxprocs"`
+}
+
+// TraceRequest selects how long /system/debug/trace records
+type TraceRequest struct {
+	Seconds int `query:"seconds" validate:"omitempty,min=1,max=60" doc:"Capture duration, 5 by default"`
+}
+
+// HeapRequest controls the heap snapshot of /system/debug/heap
+type HeapRequest struct {
+	GC bool `query:"gc" doc:"Run a garbage collection before taking the snapshot"`
+}
+
+// PprofIndex lists the available profiles; profile links are relative, so it works
+// under any mount point
+func PprofIndex(w http.ResponseWriter, r *http.Request) {
+	pprof.Index(w, r)
+}
+
+// PprofProfile serves the runtime profile named in the {name} path parameter, e.g.
+// heap, goroutine, mutex or block. pprof.Index can't be used directly because it
+// expects to be mounted at /debug/pprof/.
+func PprofProfile(w http.ResponseWriter, r *http.Request) {
+	pprof.Handler(chi.URLParam(r, "name")).ServeHTTP(w, r)
+}
+
+// Goroutines dumps the stacks of all goroutines as text
+func Goroutines(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	query.Set("debug", "2")
+	r2 := r.Clone(r.Context())
+	r2.URL.RawQuery = query.Encode()
+	pprof.Handler("goroutine").ServeHTTP(w, r2)
+}
+
+// GCStats reports garbage collector and heap statistics
+func GCStats(ctx context.Context, _ *endpoint.Empty) (GCStatsResponse, error) {
+	var stats debug.GCStats
+	debug.ReadGCStats(&stats)
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	settings := []metrics.Sample{{Name: "/gc/gogc:percent"}, {Name: "/gc/gomemlimit:bytes"}}
+	metrics.Read(settings)
+
+	pauses := make([]string, 0, min(len(stats.Pause), 10))
+	for _, p := range stats.Pause[:min(len(stats.Pause), 10)] {
+		pauses = append(pauses, p.String())
+	}
+
+	resp := GCStatsResponse{
+		NumGC:         stats.NumGC,
+		PauseTotal:    stats.PauseTotal.String(),
+		RecentPauses:  pauses,
+		HeapAlloc:     mem.HeapAlloc,
+		HeapInuse:     mem.HeapInuse,
+		HeapObjects:   mem.HeapObjects,
+		NextGC:        mem.NextGC,
+		Sys:           mem.Sys,
+		Goroutines:    runtime.NumGoroutine(),
+		GCCPUFraction: mem.GCCPUFraction,
+		GOGC:          int(settings[0].Value.Uint64()),
+		GOMemoryLimit: int64(settings[1].Value.Uint64()),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+	}
+	if !stats.LastGC.IsZero() {
+		resp.LastGC = stats.LastGC.UTC().Format(time.RFC3339Nano)
+	}
+	return resp, nil
+}
+
+// Trace records an execution trace for ?seconds=N (default 5, at most 60) and sends
+// it as a download for `go tool trace`
+func Trace(service string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req TraceRequest
+		if err := request.Bind(r, &req); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		if req.Seconds == 0 {
+			req.Seconds = 5
+		}
+
+		// Only one trace can run at a time; Start fails while pprof or another request traces
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", attachment(service, "trace", "out"))
+		if err := trace.Start(w); err != nil {
+			w.Header().Del("Content-Disposition")
+			problem.Error(w, r, apperrors.Conflict("trace_in_progress", "an execution trace is already running").Wrap(err))
+			return
+		}
+
+		select {
+		case <-time.After(time.Duration(req.Seconds) * time.Second):
+		case <-r.Context().Done():
+		}
+		trace.Stop()
+	}
+}
+
+// HeapSnapshot sends a heap profile as a download for `go tool pprof`; ?gc=true runs
+// a garbage collection first so the profile reflects live objects only
+func HeapSnapshot(service string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req HeapRequest
+		if err := request.Bind(r, &req); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		if req.GC {
+			runtime.GC()
+		}
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", attachment(service, "heap", "pb.gz"))
+		if err := rpprof.Lookup("heap").WriteTo(w, 0); err != nil {
+			meta.LoggerFromContext(r.Context()).ErrorContext(r.Context(), "failed to write heap profile", "error", err)
+		}
+	}
+}
+
+// attachment builds a Content-Disposition value like
+// attachment; filename="svc-heap-20240102T150405Z.pb.gz"
+func attachment(service, kind, ext string) string {
+	return fmt.Sprintf(`attachment; filename="%s-%s-%s.%s"`, service, kind, time.Now().UTC().Format("20060102T150405Z"), ext)
+}
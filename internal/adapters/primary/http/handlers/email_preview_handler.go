@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/adapters/primary/http/request"
+	"go-chi-boilerplate/internal/adapters/primary/http/response"
+	"go-chi-boilerplate/internal/adapters/secondary/external/email/templates"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"net/http"
+)
+
+// EmailTemplatesResponse lists the email templates available for preview
+type EmailTemplatesResponse struct {
+	Templates []string `json:"templates"`
+}
+
+// EmailPreviewRequest selects the template, locale and output format of a preview
+type EmailPreviewRequest struct {
+	Name   string `path:"name" validate:"required"`
+	Locale string `query:"locale" validate:"omitempty,bcp47_language_tag"`
+	Format string `query:"format" validate:"omitempty,oneof=html text json"`
+}
+
+// EmailTemplates lists the email templates available for preview
+func EmailTemplates(renderer *templates.Renderer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		names, err := renderer.Templates()
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		response.OK(w, r, EmailTemplatesResponse{Templates: names})
+	}
+}
+
+// EmailPreview renders an email template with its sample data.
+// Query parameters: locale (e.g. fr-CA) and format (html, text or json).
+func EmailPreview(renderer *templates.Renderer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req EmailPreviewRequest
+		if err := request.Bind(r, &req); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		data, err := renderer.SampleData(req.Name)
+		if err != nil {
+			problem.Error(w, r, templateError(req.Name, err))
+			return
+		}
+
+		rendered, err := renderer.Render(req.Name, req.Locale, data)
+		if err != nil {
+			problem.Error(w, r, templateError(req.Name, err))
+			return
+		}
+
+		w.Header().Set("Content-Language", rendered.Locale)
+
+		switch req.Format {
+		case "text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("Subject: " + rendered.Subject + "\n\n" + rendered.Text))
+		case "json":
+			response.OK(w, r, rendered)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(rendered.HTML))
+		}
+	}
+}
+
+func templateError(name string, err error) error {
+	if errors.Is(err, templates.ErrTemplateNotFound) {
+		return apperrors.NotFound("email_template_not_found", "email template "+name+" not found").Wrap(err)
+	}
+	return err
+}
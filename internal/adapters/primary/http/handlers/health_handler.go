@@ -2,82 +2,69 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
-	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql"
+	"go-chi-boilerplate/internal/adapters/primary/http/endpoint"
+	"go-chi-boilerplate/internal/adapters/primary/http/response"
+	"go-chi-boilerplate/internal/core/health"
+	"go-chi-boilerplate/internal/meta"
 	"net/http"
-	"time"
 )
 
-// Health godoc
-// @Summary Show the health status
-// @Description Get the health status of the service
-// @Tags system
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]string
-// @Router /system/health [get]
-func Health(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	response := map[string]string{
-		"status":  "ok",
-		"message": "Working!",
-	}
+// HealthResponse is the body of /system/health
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
 
-	json.NewEncoder(w).Encode(response)
+// LivenessResponse is the body of /system/liveness
+type LivenessResponse struct {
+	Alive bool `json:"alive"`
 }
 
-// Liveness godoc
-// @Summary Show if service is alive
-// @Description Liveness probe for Kubernetes
-// @Tags system
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]bool
-// @Router /system/liveness [get]
-func Liveness(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+// ReadinessResponse is the body of /system/readiness; checks map each dependency to
+// "ok" or "unavailable"
+type ReadinessResponse struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
 
-	response := map[string]bool{
-		"alive": true,
-	}
+// Health reports that the service is up
+func Health(ctx context.Context, _ *endpoint.Empty) (HealthResponse, error) {
+	return HealthResponse{
+		Status:  "ok",
+		Message: "Working!",
+	}, nil
+}
 
-	json.NewEncoder(w).Encode(response)
+// Liveness is the liveness probe for Kubernetes
+func Liveness(ctx context.Context, _ *endpoint.Empty) (LivenessResponse, error) {
+	return LivenessResponse{Alive: true}, nil
 }
 
-// Readiness godoc
-// @Summary Show if service is ready
-// @Description Readiness probe for Kubernetes (checks DB connectivity)
-// @Tags system
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Failure 503 {object} map[string]interface{}
-// @Router /system/readiness [get]
-func Readiness(db *postgresql.PostgresDB) http.HandlerFunc {
+// Readiness is the readiness probe for Kubernetes; it runs the registered dependency
+// checks and responds 503 when one fails
+func Readiness(checker *health.Checker) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
+		report := checker.Run(r.Context())
 
-		// Short timeout to avoid stressing DB
-		ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
-		defer cancel()
+		// Failure causes are logged, not returned, so probes don't leak internals
+		checks := make(map[string]string, len(report))
+		for name, err := range report {
+			if err != nil {
+				meta.LoggerFromContext(r.Context()).WarnContext(r.Context(), "readiness check failed", "check", name, "error", err)
+				checks[name] = "unavailable"
+			} else {
+				checks[name] = "ok"
+			}
+		}
 
-		if err := db.DB.PingContext(ctx); err != nil {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"ready":     false,
-				"db_status": "unreachable",
-				"error":     err.Error(),
-			})
-			return
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
 		}
 
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"ready":     true,
-			"db_status": "ok",
+		response.JSON(w, r, status, ReadinessResponse{
+			Ready:  report.Ready(),
+			Checks: checks,
 		})
 	}
 }
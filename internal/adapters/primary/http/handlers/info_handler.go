@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/adapters/primary/http/endpoint"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/meta"
+	"time"
+)
+
+// SystemInfoResponse is the body of /system/info
+type SystemInfoResponse struct {
+	Service     string         `json:"service"`
+	Environment string         `json:"environment"`
+	Uptime      string         `json:"uptime"`
+	Build       meta.BuildInfo `json:"build"`
+}
+
+// SystemInfo returns the service name, environment, uptime and build metadata
+func SystemInfo(cfg *config.ServerConfigs) endpoint.Func[endpoint.Empty, SystemInfoResponse] {
+	return func(ctx context.Context, _ *endpoint.Empty) (SystemInfoResponse, error) {
+		return SystemInfoResponse{
+			Service:     cfg.ServiceName,
+			Environment: cfg.Environment,
+			Uptime:      meta.Uptime().Truncate(time.Second).String(),
+			Build:       meta.Build(),
+		}, nil
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/adapters/primary/http/endpoint"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/core/auth"
+	"time"
+)
+
+// PrincipalResponse is the body of /api/v2/me
+type PrincipalResponse struct {
+	Subject   string     `json:"subject"`
+	Issuer    string     `json:"issuer,omitempty"`
+	Audience  []string   `json:"audience,omitempty"`
+	Scopes    []string   `json:"scopes"`
+	Roles     []string   `json:"roles"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Method    string     `json:"method" doc:"How the caller authenticated, e.g. jwt"`
+}
+
+// GetMe returns the authenticated caller
+func GetMe(ctx context.Context, _ *endpoint.Empty) (PrincipalResponse, error) {
+	p, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return PrincipalResponse{}, apperrors.Unauthorized("unauthenticated", "authentication is required")
+	}
+
+	resp := PrincipalResponse{
+		Subject:  p.Subject,
+		Issuer:   p.Issuer,
+		Audience: p.Audience,
+		Scopes:   nonNil(p.Scopes),
+		Roles:    nonNil(p.Roles),
+		Method:   p.Method,
+	}
+	if !p.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &p.ExpiresAt
+	}
+	return resp, nil
+}
+
+// nonNil makes empty lists encode as [] instead of null
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
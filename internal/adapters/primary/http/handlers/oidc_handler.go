@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"go-chi-boilerplate/internal/adapters/primary/http/endpoint"
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/adapters/primary/http/request"
+	"go-chi-boilerplate/internal/adapters/primary/http/session"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/oidc"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// loginCookie holds the state of a login in progress between /auth/login and the
+// callback; it must outlive the time the user spends at the provider
+const (
+	loginCookie    = "oidc_login"
+	loginCookieTTL = 10 * time.Minute
+)
+
+// LoginRequest starts a login
+type LoginRequest struct {
+	ReturnTo string `query:"return_to" doc:"Local path to return to after signing in" validate:"omitempty,startswith=/"`
+}
+
+// CallbackRequest is the redirect back from the identity provider
+type CallbackRequest struct {
+	Code             string `query:"code"`
+	State            string `query:"state"`
+	Error            string `query:"error"`
+	ErrorDescription string `query:"error_description"`
+}
+
+// loginState is kept in the login cookie until the callback
+type loginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	ReturnTo string `json:"return_to"`
+}
+
+// OIDCLogin redirects to the identity provider with a fresh state, nonce and PKCE
+// code verifier, which are kept in a short-lived sealed cookie
+func OIDCLogin(client *oidc.Client, cookies *session.Cookies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req LoginRequest
+		if err := request.Bind(r, &req); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		state := loginState{ReturnTo: localPath(req.ReturnTo)}
+		for _, field := range []*string{&state.State, &state.Nonce, &state.Verifier} {
+			value, err := oidc.RandomString()
+			if err != nil {
+				problem.Error(w, r, apperrors.Internal(err))
+				return
+			}
+			*field = value
+		}
+
+		authURL, err := client.AuthCodeURL(r.Context(), state.State, state.Nonce, state.Verifier)
+		if err != nil {
+			problem.Error(w, r, apperrors.Internal(err))
+			return
+		}
+		if err := cookies.Write(w, r, loginCookie, state, loginCookieTTL); err != nil {
+			problem.Error(w, r, apperrors.Internal(err))
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, authURL, http.StatusFound)
+	}
+}
+
+// OIDCCallback completes a login: it checks the state, redeems the code with the PKCE
+// verifier, verifies the ID token and its nonce, starts the session and redirects to
+// the page the login started from
+func OIDCCallback(client *oidc.Client, cookies *session.Cookies, sessions session.Store, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req CallbackRequest
+		if err := request.Bind(r, &req); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		var state loginState
+		err := cookies.Read(r, loginCookie, &state)
+		cookies.Delete(w, r, loginCookie)
+		if err != nil {
+			problem.Error(w, r, loginFailed("the login expired or was started in another browser").Wrap(err))
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(req.State), []byte(state.State)) != 1 {
+			problem.Error(w, r, loginFailed("the login state does not match"))
+			return
+		}
+		if req.Error != "" {
+			logger.InfoContext(r.Context(), "oidc login refused by provider", "error", req.Error, "description", req.ErrorDescription)
+			problem.Error(w, r, loginFailed("the identity provider refused the login"))
+			return
+		}
+		if req.Code == "" {
+			problem.Error(w, r, apperrors.BadRequest("missing_code", "the callback has no authorization code"))
+			return
+		}
+
+		tokens, err := client.Exchange(r.Context(), req.Code, state.Verifier)
+		if err != nil {
+			problem.Error(w, r, callbackError(err))
+			return
+		}
+		if tokens.IDToken == "" {
+			problem.Error(w, r, loginFailed("the identity provider returned no ID token"))
+			return
+		}
+		claims, err := client.VerifyIDToken(r.Context(), tokens.IDToken, state.Nonce)
+		if err != nil {
+			problem.Error(w, r, loginFailed("the ID token is invalid").Wrap(err))
+			return
+		}
+
+		// A new session ID on sign-in defeats session fixation
+		sess := oidc.NewSession(tokens, claims)
+		if err := sessions.Renew(w, r, sess); err != nil {
+			problem.Error(w, r, apperrors.Internal(err))
+			return
+		}
+		logger.InfoContext(r.Context(), "user signed in", "subject", sess.User().Subject)
+
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, state.ReturnTo, http.StatusFound)
+	}
+}
+
+// CSRFTokenResponse is the body of /auth/csrf
+type CSRFTokenResponse struct {
+	Token string `json:"token" doc:"Send as X-CSRF-Token or the csrf_token form field"`
+}
+
+// GetCSRFToken returns the CSRF token of the caller's session, see middleware.CSRF
+func GetCSRFToken(token func(ctx context.Context) string) endpoint.Func[endpoint.Empty, CSRFTokenResponse] {
+	return func(ctx context.Context, _ *endpoint.Empty) (CSRFTokenResponse, error) {
+		t := token(ctx)
+		if t == "" {
+			return CSRFTokenResponse{}, apperrors.Unauthorized("unauthenticated", "signing in is required")
+		}
+		return CSRFTokenResponse{Token: t}, nil
+	}
+}
+
+// OIDCLogout ends the session and, when the provider supports RP-initiated logout,
+// redirects there to end the provider session too
+func OIDCLogout(client *oidc.Client, sessions session.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var sess oidc.Session
+		loaded := sessions.Load(r, &sess) == nil
+		if err := sessions.Clear(w, r); err != nil {
+			problem.Error(w, r, apperrors.Internal(err))
+			return
+		}
+
+		target := "/"
+		if loaded {
+			endSession, err := client.EndSessionURL(r.Context(), sess.IDToken)
+			if err != nil {
+				problem.Error(w, r, apperrors.Internal(err))
+				return
+			}
+			if endSession != "" {
+				target = endSession
+			}
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, target, http.StatusSeeOther)
+	}
+}
+
+// localPath guards against open redirects: only paths on this host are returned to
+func localPath(p string) string {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") || strings.HasPrefix(p, "/\\") {
+		return "/"
+	}
+	return p
+}
+
+func loginFailed(message string) *apperrors.Error {
+	return apperrors.Unauthorized("login_failed", message)
+}
+
+func callbackError(err error) error {
+	var tokenErr *oidc.TokenError
+	if errors.As(err, &tokenErr) && tokenErr.Code == "invalid_grant" {
+		return loginFailed("the authorization code is invalid or was already used").Wrap(err)
+	}
+	return apperrors.Internal(err)
+}
@@ -1,16 +1,52 @@
 package handlers
 
 import (
+	"html/template"
 	"net/http"
 
-	httpSwagger "github.com/swaggo/http-swagger"
+	"github.com/go-chi/chi/v5"
+	swaggerfiles "github.com/swaggo/files/v2"
 )
 
-func SwaggerHandler() http.Handler {
-	return httpSwagger.Handler(
-		httpSwagger.URL("/system/swagger/doc.json"),
-		httpSwagger.DeepLinking(true),
-		httpSwagger.DocExpansion("none"),
-		httpSwagger.PersistAuthorization(true),
-	)
+// The Swagger UI assets are embedded from swagger-ui-dist 5.x, which renders OpenAPI 3.1;
+// nothing is loaded from a CDN, so the page can't be served tampered scripts
+var swaggerUIPage = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>API documentation</title>
+  <link rel="stylesheet" href="swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({
+      url: {{.SpecURL}},
+      dom_id: "#swagger-ui",
+      deepLinking: true,
+      docExpansion: "none",
+      persistAuthorization: true,
+    });
+  </script>
+</body>
+</html>
+`))
+
+// SwaggerUI renders Swagger UI for the OpenAPI document served at specURL; mount it on
+// a wildcard route such as /swagger/*, below which it serves its assets
+func SwaggerUI(specURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if asset := chi.URLParam(r, "*"); asset != "" && asset != "index.html" {
+			http.ServeFileFS(w, r, swaggerfiles.FS, asset)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+
+		swaggerUIPage.Execute(w, map[string]string{
+			"SpecURL": specURL,
+		})
+	}
 }
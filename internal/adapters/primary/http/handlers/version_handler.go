@@ -1,30 +1,22 @@
 package handlers
 
 import (
-	"encoding/json"
-	"net/http"
-	"os"
+	"context"
+	"go-chi-boilerplate/internal/adapters/primary/http/endpoint"
+	"go-chi-boilerplate/internal/meta"
 )
 
-// APIVersion returns the current API version
-// @Summary Get API version
-// @Description Returns the current version of the API in JSON format
-// @Tags api
-// @Produce json
-// @Success 200 {object} map[string]string "API version"
-// @Router /api/version [get]
-func APIVersion(w http.ResponseWriter, r *http.Request) {
-	apiVersion := os.Getenv("API_VERSION")
-	if apiVersion == "" {
-		apiVersion = "v1.0.0"
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+// VersionResponse is the body of /api/v1/version
+type VersionResponse struct {
+	Version string `json:"version" doc:"Version of the running build"`
+}
 
-	response := map[string]string{
-		"version": apiVersion,
-	}
+// GetVersion returns the build metadata of the running binary
+func GetVersion(ctx context.Context, _ *endpoint.Empty) (meta.BuildInfo, error) {
+	return meta.Build(), nil
+}
 
-	json.NewEncoder(w).Encode(response)
+// GetVersionV1 returns only the version, the response shape of API v1
+func GetVersionV1(ctx context.Context, _ *endpoint.Empty) (VersionResponse, error) {
+	return VersionResponse{Version: meta.Version()}, nil
 }
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/core/auth"
+	"go-chi-boilerplate/internal/core/ports"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader carries an API key; "Authorization: ApiKey <key>" is accepted as well
+const APIKeyHeader = "X-API-Key"
+
+// AuthenticateAPIKey requires a valid API key and stores its principal in the request
+// context; other requests get a 401 problem
+func AuthenticateAPIKey(verifier ports.TokenVerifier) func(http.Handler) http.Handler {
+	return authenticateAPIKey(verifier, true)
+}
+
+// AuthenticateAPIKeyOptional stores the principal of a valid API key in the request
+// context and lets requests without one through, e.g. to Authenticate for bearer
+// tokens; invalid keys still get a 401 problem
+func AuthenticateAPIKeyOptional(verifier ports.TokenVerifier) func(http.Handler) http.Handler {
+	return authenticateAPIKey(verifier, false)
+}
+
+func authenticateAPIKey(verifier ports.TokenVerifier, required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := apiKey(r)
+			if key == "" {
+				if !required {
+					next.ServeHTTP(w, r)
+					return
+				}
+				w.Header().Set("WWW-Authenticate", `ApiKey realm="api"`)
+				problem.Error(w, r, apperrors.Unauthorized("unauthenticated", "an API key is required"))
+				return
+			}
+
+			principal, err := verifier.Verify(r.Context(), key)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `ApiKey realm="api", error="invalid_key"`)
+				problem.Error(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func apiKey(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get(APIKeyHeader)); key != "" {
+		return key
+	}
+	scheme, key, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/core/auth"
+	"go-chi-boilerplate/internal/core/ports"
+	"net/http"
+	"strings"
+)
+
+// Authenticate requires a valid "Authorization: Bearer <token>" header and stores the
+// verified principal in the request context; other requests get a 401 problem
+func Authenticate(verifier ports.TokenVerifier) func(http.Handler) http.Handler {
+	return authenticate(verifier, true)
+}
+
+// AuthenticateOptional stores the principal of a valid bearer token in the request
+// context and lets requests without an Authorization header through anonymously;
+// invalid tokens still get a 401 problem
+func AuthenticateOptional(verifier ports.TokenVerifier) func(http.Handler) http.Handler {
+	return authenticate(verifier, false)
+}
+
+func authenticate(verifier ports.TokenVerifier, required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// A principal from an earlier middleware, e.g. an API key, is kept
+			if _, ok := auth.PrincipalFromContext(r.Context()); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := r.Header.Get("Authorization")
+			if header == "" && !required {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme, token, _ := strings.Cut(header, " ")
+			if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				problem.Error(w, r, apperrors.Unauthorized("unauthenticated", "a bearer token is required"))
+				return
+			}
+
+			principal, err := verifier.Verify(r.Context(), strings.TrimSpace(token))
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+				problem.Error(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/core/auth"
+	"go-chi-boilerplate/internal/core/ports"
+	"go-chi-boilerplate/internal/meta"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RequireScopes lets a request through only when its principal has every one of the
+// scopes; it must run after Authenticate. Missing scopes get a 403 problem with an
+// RFC 6750 insufficient_scope challenge.
+func RequireScopes(scopes ...string) func(http.Handler) http.Handler {
+	requirement := strings.Join(scopes, " ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := principal(w, r)
+			if !ok {
+				return
+			}
+
+			for _, scope := range scopes {
+				if !p.HasScope(scope) {
+					recordDecision(r, "scopes", requirement, auth.Deny("missing scope "+scope))
+					w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="insufficient_scope", scope="`+requirement+`"`)
+					problem.Error(w, r, apperrors.Forbidden("insufficient_scope", "the access token lacks the required scopes: "+requirement))
+					return
+				}
+			}
+
+			recordDecision(r, "scopes", requirement, auth.Allow("has scopes"))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRoles lets a request through only when its principal has at least one of the
+// roles; it must run after Authenticate
+func RequireRoles(roles ...string) func(http.Handler) http.Handler {
+	requirement := strings.Join(roles, " ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := principal(w, r)
+			if !ok {
+				return
+			}
+
+			for _, role := range roles {
+				if p.HasRole(role) {
+					recordDecision(r, "roles", requirement, auth.Allow("role "+role))
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			recordDecision(r, "roles", requirement, auth.Deny("has none of the roles"))
+			problem.Error(w, r, apperrors.Forbidden("forbidden", "you are not allowed to perform this action"))
+		})
+	}
+}
+
+// Authorize asks engine whether the principal may perform action; the resource
+// attributes are the route's URL parameters, so register it with With on the route.
+// Handlers that need attributes of the loaded object call the engine themselves.
+func Authorize(engine ports.PolicyEngine, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := principal(w, r)
+			if !ok {
+				return
+			}
+
+			resource := map[string]any{}
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				for i, key := range rctx.URLParams.Keys {
+					resource[key] = rctx.URLParams.Values[i]
+				}
+			}
+
+			// Evaluation errors, e.g. a rule reading a missing claim, fail closed
+			decision, err := engine.Evaluate(r.Context(), auth.Request{Principal: p, Action: action, Resource: resource})
+			if err != nil {
+				recordDecision(r, "policy", action, auth.Deny("evaluation failed: "+err.Error()))
+				problem.Error(w, r, apperrors.Forbidden("forbidden", "you are not allowed to perform this action").Wrap(err))
+				return
+			}
+
+			recordDecision(r, "policy", action, decision)
+			if !decision.Allowed {
+				problem.Error(w, r, apperrors.Forbidden("forbidden", "you are not allowed to perform this action"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePrincipal lets only requests authenticated by an earlier middleware through,
+// e.g. when a route accepts API keys or sessions but no bearer tokens
+func RequirePrincipal(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := principal(w, r); ok {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// principal returns the authenticated principal or writes a 401 problem
+func principal(w http.ResponseWriter, r *http.Request) (*auth.Principal, bool) {
+	p, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+		problem.Error(w, r, apperrors.Unauthorized("unauthenticated", "authentication is required"))
+	}
+	return p, ok
+}
+
+// recordDecision counts the decision and logs it; denials at info level, grants at debug
+func recordDecision(r *http.Request, mechanism, requirement string, d auth.Decision) {
+	result := "deny"
+	if d.Allowed {
+		result = "allow"
+	}
+	meta.AuthzDecisionsTotal.WithLabelValues(mechanism, requirement, result).Inc()
+
+	p, _ := auth.PrincipalFromContext(r.Context())
+	attrs := []any{
+		"mechanism", mechanism,
+		"requirement", requirement,
+		"decision", result,
+		"reason", d.Reason,
+		"subject", p.Subject,
+		"method", r.Method,
+		"path", r.URL.Path,
+	}
+
+	logger := meta.LoggerFromContext(r.Context())
+	if d.Allowed {
+		logger.DebugContext(r.Context(), "authorization decision", attrs...)
+	} else {
+		logger.InfoContext(r.Context(), "authorization decision", attrs...)
+	}
+}
@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/core/ports"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CacheOptions controls how ResponseCache keys and stores responses
+type CacheOptions struct {
+	// TTL is how long a response is stored and advertised in Cache-Control max-age
+	TTL time.Duration
+	// KeyPrefix namespaces the entries in the cache
+	KeyPrefix string
+	// IncludeQuery adds the query string to the cache key
+	IncludeQuery bool
+	// VaryHeaders are request headers added to the cache key and the Vary response header
+	VaryHeaders []string
+	// UserKey returns the caller identity; when set, responses are cached per user and marked private
+	UserKey func(r *http.Request) string
+}
+
+type cachedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	ETag   string      `json:"etag"`
+}
+
+// bufferedWriter holds the response back so it can be hashed and stored before being sent
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (bw *bufferedWriter) WriteHeader(code int) {
+	if bw.status == 0 {
+		bw.status = code
+	}
+}
+
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	return bw.body.Write(b)
+}
+
+// ResponseCache returns a route-level middleware that caches successful GET responses,
+// generates strong ETags and answers If-None-Match with 304 Not Modified.
+// When c is nil responses are not stored but ETags and conditional requests still work.
+func ResponseCache(c ports.Cache, logger *slog.Logger, opts CacheOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := opts.cacheKey(r)
+			noCache := strings.Contains(r.Header.Get("Cache-Control"), "no-cache")
+
+			if c != nil && !noCache {
+				if entry, ok := loadResponse(r, c, key, logger); ok {
+					w.Header().Set("X-Cache", "HIT")
+					opts.writeCached(w, r, entry)
+					return
+				}
+			}
+
+			bw := &bufferedWriter{ResponseWriter: w}
+			next.ServeHTTP(bw, r)
+			if bw.status == 0 {
+				bw.status = http.StatusOK
+			}
+
+			// Responses the handler marked private or no-store never go into the shared cache
+			cacheControl := w.Header().Get("Cache-Control")
+			uncacheable := strings.Contains(cacheControl, "no-store") || strings.Contains(cacheControl, "private")
+			if bw.status != http.StatusOK || w.Header().Get("Set-Cookie") != "" || uncacheable {
+				w.WriteHeader(bw.status)
+				w.Write(bw.body.Bytes())
+				return
+			}
+
+			entry := &cachedResponse{
+				Status: bw.status,
+				Header: storableHeaders(w.Header()),
+				Body:   bw.body.Bytes(),
+				ETag:   strongETag(bw.body.Bytes()),
+			}
+
+			if c != nil {
+				storeResponse(r, c, key, entry, opts.TTL, logger)
+				w.Header().Set("X-Cache", "MISS")
+			}
+
+			opts.writeCached(w, r, entry)
+		})
+	}
+}
+
+func (o CacheOptions) cacheKey(r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(r.URL.Path)
+
+	if o.IncludeQuery {
+		b.WriteString("?")
+		b.WriteString(r.URL.Query().Encode()) // Encode sorts by key
+	}
+
+	for _, h := range o.VaryHeaders {
+		fmt.Fprintf(&b, "\n%s:%s", strings.ToLower(h), r.Header.Get(h))
+	}
+
+	if o.UserKey != nil {
+		fmt.Fprintf(&b, "\nuser:%s", o.UserKey(r))
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return o.KeyPrefix + "httpcache:" + hex.EncodeToString(sum[:])
+}
+
+func (o CacheOptions) writeCached(w http.ResponseWriter, r *http.Request, entry *cachedResponse) {
+	h := w.Header()
+	for k, v := range entry.Header {
+		if _, ok := h[k]; !ok {
+			h[k] = v
+		}
+	}
+
+	h.Set("ETag", entry.ETag)
+	if h.Get("Cache-Control") == "" {
+		visibility := "public"
+		if o.UserKey != nil {
+			visibility = "private"
+		}
+		h.Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, int(o.TTL.Seconds())))
+	}
+	// Keep the Vary values of earlier middlewares such as NegotiateVersion
+	for _, name := range o.VaryHeaders {
+		if !varies(h, name) {
+			h.Add("Vary", name)
+		}
+	}
+
+	if etagMatches(r.Header.Get("If-None-Match"), entry.ETag) {
+		h.Del("Content-Length")
+		h.Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(entry.Status)
+	w.Write(entry.Body)
+}
+
+// varies reports whether the Vary header of h already names the request header name
+func varies(h http.Header, name string) bool {
+	for _, value := range h.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func loadResponse(r *http.Request, c ports.Cache, key string, logger *slog.Logger) (*cachedResponse, bool) {
+	data, err := c.Get(r.Context(), key)
+	if err != nil {
+		if !errors.Is(err, ports.ErrCacheMiss) {
+			logger.Warn("failed to read cached response", "path", r.URL.Path, "error", err)
+		}
+		return nil, false
+	}
+
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		logger.Warn("failed to decode cached response", "path", r.URL.Path, "error", err)
+		return nil, false
+	}
+	return &entry, true
+}
+
+func storeResponse(r *http.Request, c ports.Cache, key string, entry *cachedResponse, ttl time.Duration, logger *slog.Logger) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logger.Warn("failed to encode response for cache", "path", r.URL.Path, "error", err)
+		return
+	}
+	if err := c.Set(r.Context(), key, data, ttl); err != nil {
+		logger.Warn("failed to store cached response", "path", r.URL.Path, "error", err)
+	}
+}
+
+// storableHeaders keeps the headers that describe the representation itself
+func storableHeaders(h http.Header) http.Header {
+	out := http.Header{}
+	for _, k := range []string{"Content-Type", "Content-Language", "Content-Encoding", "Last-Modified"} {
+		if v := h.Values(k); len(v) > 0 {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+}
+
+// etagMatches implements the weak comparison RFC 9110 requires for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	for _, t := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(t), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
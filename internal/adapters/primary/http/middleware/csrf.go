@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/adapters/primary/http/session"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/core/auth"
+	"mime"
+	"net/http"
+)
+
+// CSRFHeader and CSRFField carry the token of CSRFToken on unsafe requests
+const (
+	CSRFHeader = "X-CSRF-Token"
+	CSRFField  = "csrf_token"
+)
+
+type csrfKey struct{}
+
+// CSRF rejects POST, PUT, PATCH and DELETE requests authenticated by the session
+// cookie unless they carry the CSRF token of the session in the X-CSRF-Token header
+// or the csrf_token form field, and makes the token available through CSRFToken.
+// Requests authenticated by a bearer token or API key are not affected: browsers
+// don't send those by themselves. Put it after LoadUser and the other authentication
+// middlewares.
+func CSRF(cookies *session.Cookies, sessions session.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cookieAuthenticated(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			id, err := sessions.ID(r)
+			if err != nil {
+				problem.Error(w, r, apperrors.Forbidden("csrf_failed", "the session has ended").Wrap(err))
+				return
+			}
+
+			if !safeMethod(r.Method) && !cookies.VerifyCSRF(id, sentCSRFToken(r)) {
+				problem.Error(w, r, apperrors.Forbidden("csrf_failed", "the CSRF token is missing or invalid"))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), csrfKey{}, cookies.CSRFToken(id))))
+		})
+	}
+}
+
+// sentCSRFToken reads the token from the header, and only parses the body of form
+// submissions that have none
+func sentCSRFToken(r *http.Request) string {
+	if token := r.Header.Get(CSRFHeader); token != "" {
+		return token
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data" {
+		return r.PostFormValue(CSRFField)
+	}
+	return ""
+}
+
+// CSRFToken returns the token to embed in forms or send as X-CSRF-Token; it is empty
+// outside CSRF and for requests not authenticated by the session cookie
+func CSRFToken(ctx context.Context) string {
+	token, _ := ctx.Value(csrfKey{}).(string)
+	return token
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+// cookieAuthenticated reports whether the principal of r comes from the session cookie
+func cookieAuthenticated(r *http.Request) bool {
+	p, ok := auth.PrincipalFromContext(r.Context())
+	return ok && p.Method == "oidc"
+}
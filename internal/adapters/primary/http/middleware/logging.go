@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"fmt"
+	"go-chi-boilerplate/internal/meta"
 	"log/slog"
 	"net/http"
 	"path/filepath"
@@ -34,6 +35,9 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 func LoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Handlers and problem responses log through the request-scoped logger
+			r = r.WithContext(meta.WithLogger(r.Context(), logger))
+
 			// Skip /system/health if desired
 			if r.URL.Path == "/system/health" {
 				next.ServeHTTP(w, r)
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/meta"
+	"net/http"
+)
+
+// ProfileLabels labels the CPU samples of the request with its span ID so profiles can
+// be opened from the trace; it must run after the tracing middleware
+func ProfileLabels(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		meta.WithSpanProfileLabels(r.Context(), func(ctx context.Context) {
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/core/auth"
+	"go-chi-boilerplate/internal/core/domain"
+	"go-chi-boilerplate/internal/core/ports"
+	"go-chi-boilerplate/internal/meta"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RateLimitKey returns the key a request is counted under; requests with the same key
+// share a quota
+type RateLimitKey func(r *http.Request) string
+
+// KeyByIP counts requests per client address, taken from RemoteAddr as in RequireAccess
+func KeyByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// KeyByAPIKey counts requests per API key and requests without one per client
+// address. Only a hash of the key is used. Invalid keys are counted too, but as every
+// guess is a new key, use LimitFailedAuth against brute force.
+func KeyByAPIKey(r *http.Request) string {
+	key := apiKey(r)
+	if key == "" {
+		return KeyByIP(r)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "key:" + hex.EncodeToString(sum[:16])
+}
+
+// KeyByUser counts requests per authenticated principal and anonymous requests per
+// client address; put it after the authentication middlewares
+func KeyByUser(r *http.Request) string {
+	if p, ok := auth.PrincipalFromContext(r.Context()); ok && p.Subject != "" {
+		return "user:" + p.Method + ":" + p.Subject
+	}
+	return KeyByIP(r)
+}
+
+// KeyByRoute gives every route pattern and method its own quota; combine it with a
+// client key, e.g. KeyBy(KeyByIP, KeyByRoute), to limit each client per route
+func KeyByRoute(r *http.Request) string {
+	return "route:" + r.Method + " " + routePattern(r)
+}
+
+// KeyBy combines keys, e.g. to count per user and route
+func KeyBy(keys ...RateLimitKey) RateLimitKey {
+	return func(r *http.Request) string {
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			// Client keys fall back to the address, which must only count once
+			if part := key(r); !slices.Contains(parts, part) {
+				parts = append(parts, part)
+			}
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// RateLimitKeys maps the names RATE_LIMIT_KEY accepts to their keys
+var RateLimitKeys = map[string]RateLimitKey{
+	"ip":      KeyByIP,
+	"api_key": KeyByAPIKey,
+	"user":    KeyByUser,
+	"route":   KeyByRoute,
+}
+
+// RateLimit counts requests against limit under key and rejects those over it with a
+// 429 problem and Retry-After. Every response carries the RateLimit-Limit,
+// RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers of the IETF
+// draft. When the store fails the request is let through: an outage of Redis
+// should not take the API down with it.
+func RateLimit(limiter ports.RateLimiter, limit domain.RateLimit, key RateLimitKey) func(http.Handler) http.Handler {
+	policy := fmt.Sprintf("%d;w=%d", limit.Limit, int(math.Ceil(limit.Window.Seconds())))
+	if limit.Algorithm == domain.TokenBucket && limit.Burst > 0 {
+		policy += ";burst=" + strconv.Itoa(limit.Burst)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := limiter.Allow(r.Context(), key(r), limit)
+			if err != nil {
+				meta.HTTPRateLimiterErrorsTotal.Inc()
+				meta.LoggerFromContext(r.Context()).WarnContext(r.Context(), "rate limiter failed, request let through", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(limit.Capacity()))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			h.Set("RateLimit-Policy", policy)
+
+			if !res.Allowed {
+				meta.HTTPRateLimitedRequestsTotal.WithLabelValues(r.Method, routePattern(r)).Inc()
+				problem.Error(w, r, apperrors.RateLimited("too many requests, retry later", res.RetryAfter))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LimitFailedAuth rejects clients that failed authentication limit times with a 429
+// problem before their credentials are checked again; a failure is a 401 response of
+// the handlers after it. Put it in front of the authentication middlewares, keyed by
+// address: attackers trying many keys or tokens would get a fresh quota per guess if
+// counted by credential.
+func LimitFailedAuth(limiter ports.RateLimiter, limit domain.RateLimit, key RateLimitKey) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			failures := "auth_failures:" + key(r)
+
+			res, err := limiter.Peek(ctx, failures, limit)
+			if err != nil {
+				meta.HTTPRateLimiterErrorsTotal.Inc()
+				meta.LoggerFromContext(ctx).WarnContext(ctx, "rate limiter failed, request let through", "error", err)
+			} else if !res.Allowed {
+				meta.HTTPRateLimitedRequestsTotal.WithLabelValues(r.Method, routePattern(r)).Inc()
+				problem.Error(w, r, apperrors.RateLimited("too many failed authentication attempts, retry later", res.RetryAfter))
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			if rec.status != http.StatusUnauthorized {
+				return
+			}
+			if _, err := limiter.Allow(ctx, failures, limit); err != nil {
+				meta.HTTPRateLimiterErrorsTotal.Inc()
+				meta.LoggerFromContext(ctx).WarnContext(ctx, "failed to count authentication failure", "error", err)
+			}
+		})
+	}
+}
+
+// routePattern is the chi pattern of the matched route; the full pattern is only
+// known to middlewares added with With on the route itself
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
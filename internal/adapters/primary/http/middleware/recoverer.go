@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/meta"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recoverer converts panics into an internal server error problem response and logs
+// the panic with its stack through the request-scoped logger
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			// Let net/http abort the connection as intended
+			if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(p)
+			}
+
+			meta.LoggerFromContext(r.Context()).ErrorContext(r.Context(), "panic recovered in http handler",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"panic", p,
+				"stack", string(debug.Stack()),
+			)
+			if r.Header.Get("Connection") != "Upgrade" {
+				problem.Write(w, r, problem.Problem{
+					Status: http.StatusInternalServerError,
+					Code:   "internal",
+					Detail: "internal server error",
+				})
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"errors"
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/adapters/primary/http/session"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/oidc"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/core/auth"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// LoadUser reads the OIDC session of the request and stores the signed-in user in the
+// request context, see auth.CurrentUser; the user also becomes the request principal
+// unless an earlier middleware set one. Access tokens about to expire are refreshed;
+// a rejected refresh token ends the session once the access token has expired.
+// Requests without a session pass through.
+func LoadUser(client *oidc.Client, sessions session.Store, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			var sess oidc.Session
+			if err := sessions.Load(r, &sess); err != nil {
+				if !errors.Is(err, session.ErrNotFound) {
+					logger.WarnContext(ctx, "failed to load session", "error", err)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if sess.NeedsRefresh(time.Now()) {
+				if !refreshSession(w, r, client, sessions, &sess, logger) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			ctx = auth.WithUser(ctx, sess.User())
+			if _, ok := auth.PrincipalFromContext(ctx); !ok {
+				ctx = auth.WithPrincipal(ctx, sess.Principal(client.RolesClaim()))
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// refreshSession refreshes the tokens of sess and saves it; it returns false when the
+// session was ended because the provider rejected the refresh token of an expired
+// access token
+func refreshSession(w http.ResponseWriter, r *http.Request, client *oidc.Client, sessions session.Store, sess *oidc.Session, logger *slog.Logger) bool {
+	ctx := r.Context()
+
+	tokens, err := client.Refresh(ctx, sess.RefreshToken)
+	var tokenErr *oidc.TokenError
+	if errors.As(err, &tokenErr) && time.Now().Before(sess.Expiry) {
+		// A concurrent request may have rotated the refresh token; the access token
+		// is still valid, so keep the session until it expires
+		logger.WarnContext(ctx, "refresh token rejected, keeping session until the access token expires", "subject", sess.User().Subject, "error", err)
+		return true
+	}
+	if errors.As(err, &tokenErr) {
+		logger.InfoContext(ctx, "session ended, refresh token rejected", "subject", sess.User().Subject, "error", err)
+		if err := sessions.Clear(w, r); err != nil {
+			logger.WarnContext(ctx, "failed to clear session", "error", err)
+		}
+		return false
+	}
+	if err != nil {
+		// The provider may be down; keep the session and try again on the next request
+		logger.WarnContext(ctx, "failed to refresh session tokens", "subject", sess.User().Subject, "error", err)
+		return true
+	}
+
+	var claims map[string]any
+	if tokens.IDToken != "" {
+		if claims, err = client.VerifyIDToken(ctx, tokens.IDToken, ""); err != nil {
+			logger.WarnContext(ctx, "refreshed id token is invalid, keeping previous claims", "error", err)
+			claims = nil
+		}
+	}
+	sess.Update(tokens, claims)
+
+	if err := sessions.Save(w, r, sess); err != nil {
+		logger.WarnContext(ctx, "failed to save refreshed session", "error", err)
+		return true
+	}
+	logger.DebugContext(ctx, "session tokens refreshed", "subject", sess.User().Subject)
+	return true
+}
+
+// RequireUser lets only requests with a signed-in user through. Browsers navigating to
+// a page are redirected to loginPath with a return_to parameter; other requests get a
+// 401 problem.
+func RequireUser(loginPath string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := auth.CurrentUser(r.Context()); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html") {
+				http.Redirect(w, r, loginPath+"?return_to="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+				return
+			}
+			problem.Error(w, r, apperrors.Unauthorized("unauthenticated", "signing in is required"))
+		})
+	}
+}
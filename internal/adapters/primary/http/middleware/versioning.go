@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/meta"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// APIVersionHeader reports which API version served the response
+const APIVersionHeader = "API-Version"
+
+// versionPrefix matches a path that already names its version, e.g. /v2/users
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+(/|$)`)
+
+// NegotiateVersion routes unversioned paths to the version requested with a vendor media
+// type such as "Accept: application/vnd.go-chi-boilerplate+json;version=2". It must be
+// used on the router that mounts the /v<N> groups. Paths that already name a version, and
+// requests without a version parameter, are left alone; unsupported versions get 406.
+func NegotiateVersion(supported ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rctx := chi.RouteContext(r.Context())
+			routePath := r.URL.Path
+			if rctx != nil && rctx.RoutePath != "" {
+				routePath = rctx.RoutePath
+			}
+
+			w.Header().Add("Vary", "Accept")
+
+			version, ok := acceptedVersion(r.Header.Get("Accept"))
+			if !ok || versionPrefix.MatchString(routePath) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !slices.Contains(supported, version) {
+				problem.Write(w, r, problem.Problem{
+					Status: http.StatusNotAcceptable,
+					Code:   "unsupported_api_version",
+					Detail: "unsupported API version " + version,
+				})
+				return
+			}
+
+			// Rewrite both the routing path and the URL so caches and handlers see the
+			// versioned path
+			r = r.Clone(r.Context())
+			r.URL.Path = strings.TrimSuffix(r.URL.Path, routePath) + "/" + version + routePath
+			r.URL.RawPath = ""
+			if rctx != nil {
+				rctx.RoutePath = "/" + version + routePath
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acceptedVersion returns the version parameter of the first vendor media type in accept,
+// normalised to "v<N>"
+func acceptedVersion(accept string) (string, bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || !strings.HasPrefix(mediaType, "application/vnd.") {
+			continue
+		}
+		v := strings.TrimPrefix(strings.ToLower(params["version"]), "v")
+		if _, err := strconv.Atoi(v); err == nil {
+			return "v" + v, true
+		}
+	}
+	return "", false
+}
+
+// VersionHeader sets the API-Version response header for a version group
+func VersionHeader(version string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(APIVersionHeader, version)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Deprecation describes a deprecated version or route
+type Deprecation struct {
+	// Since is when it was deprecated; zero sends "Deprecation: true"
+	Since time.Time
+	// Sunset is when it will be removed; zero omits the Sunset header
+	Sunset time.Time
+	// Link points to the migration guide or successor version
+	Link string
+}
+
+// Deprecated adds Deprecation (RFC 9745), Sunset (RFC 8594) and Link headers and counts
+// the calls in http_deprecated_requests_total. Use it on a version group or a single route.
+func Deprecated(version string, d Deprecation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if d.Since.IsZero() {
+				w.Header().Set("Deprecation", "true")
+			} else {
+				w.Header().Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+			}
+			if !d.Sunset.IsZero() {
+				w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if d.Link != "" {
+				w.Header().Add("Link", "<"+d.Link+`>; rel="deprecation"`)
+			}
+
+			next.ServeHTTP(w, r)
+
+			// The full route pattern is only known once routing has finished
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			meta.HTTPDeprecatedRequestsTotal.WithLabelValues(version, r.Method, route).Inc()
+		})
+	}
+}
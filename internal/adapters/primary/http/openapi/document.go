@@ -0,0 +1,227 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Version is the OpenAPI version of the generated documents
+const Version = "3.1.0"
+
+// Document is an OpenAPI 3.1 document built at runtime from the registered operations
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	mu       sync.RWMutex
+	schemas  *schemaRegistry
+	external []map[string]any
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of one path, keyed by lower-case HTTP method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// New creates an empty Document
+func New(title, version string) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+		},
+	}
+	d.schemas = newSchemaRegistry(d.Components.Schemas)
+	return d
+}
+
+// AddOperation registers op for method and path; chi patterns such as /users/{id:[0-9]+}
+// are converted to OpenAPI templates
+func (d *Document) AddOperation(method, path string, op *Operation) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	path = templatePath(path)
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+// AddSecurityScheme registers a security scheme under name
+func (d *Document) AddSecurityScheme(name string, scheme *SecurityScheme) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Components.SecuritySchemes == nil {
+		d.Components.SecuritySchemes = map[string]*SecurityScheme{}
+	}
+	d.Components.SecuritySchemes[name] = scheme
+}
+
+// Handler serves the document as JSON
+func (d *Document) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := d.MarshalJSON()
+		if err != nil {
+			problem.Error(w, r, fmt.Errorf("failed to encode OpenAPI document: %w", err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}
+}
+
+// MarshalJSON encodes the document; it is safe to call while operations are added
+func (d *Document) MarshalJSON() ([]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	type document Document
+	body, err := json.Marshal((*document)(d))
+	if err != nil || len(d.external) == 0 {
+		return body, err
+	}
+
+	var merged map[string]any
+	if err := json.Unmarshal(body, &merged); err != nil {
+		return nil, err
+	}
+	paths := merged["paths"].(map[string]any)
+	components := merged["components"].(map[string]any)
+	schemas, _ := components["schemas"].(map[string]any)
+	if schemas == nil {
+		schemas = map[string]any{}
+		components["schemas"] = schemas
+	}
+
+	for _, ext := range d.external {
+		mergeMissing(paths, ext["paths"])
+		if c, ok := ext["components"].(map[string]any); ok {
+			mergeMissing(schemas, c["schemas"])
+		}
+		if tags, ok := ext["tags"].([]any); ok {
+			merged["tags"] = mergeTags(merged["tags"], tags)
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// mergeTags appends the tags whose name is not in existing yet
+func mergeTags(existing any, tags []any) []any {
+	out, _ := existing.([]any)
+	seen := map[any]bool{}
+	for _, t := range out {
+		if m, ok := t.(map[string]any); ok {
+			seen[m["name"]] = true
+		}
+	}
+	for _, t := range tags {
+		if m, ok := t.(map[string]any); ok && !seen[m["name"]] {
+			out = append(out, t)
+			seen[m["name"]] = true
+		}
+	}
+	return out
+}
+
+// mergeMissing copies the entries of src missing from dst
+func mergeMissing(dst map[string]any, src any) {
+	m, _ := src.(map[string]any)
+	for k, v := range m {
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+}
+
+// templatePath strips chi regexp constraints and the trailing wildcard from a pattern
+func templatePath(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != '{' {
+			b.WriteByte(c)
+			continue
+		}
+		end := strings.IndexByte(pattern[i:], '}')
+		if end < 0 {
+			b.WriteString(pattern[i:])
+			break
+		}
+		name, _, _ := strings.Cut(pattern[i+1:i+end], ":")
+		b.WriteString("{" + name + "}")
+		i += end
+	}
+	return strings.TrimSuffix(b.String(), "/*")
+}
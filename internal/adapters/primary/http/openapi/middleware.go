@@ -0,0 +1,292 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
+	"go-chi-boilerplate/internal/adapters/primary/http/request"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/meta"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ValidationMode selects what the validator does with schema violations
+type ValidationMode string
+
+const (
+	// ValidationEnforce rejects invalid requests and replaces invalid responses with a 500
+	ValidationEnforce ValidationMode = "enforce"
+	// ValidationLog only logs violations
+	ValidationLog ValidationMode = "log"
+	// ValidationOff disables validation
+	ValidationOff ValidationMode = "off"
+)
+
+// Validator checks requests and JSON responses of documented operations against the
+// document. Violations are reported as problems in enforce mode and only logged in log
+// mode. Paths merged from external specs are not validated.
+func (d *Document) Validator(mode ValidationMode) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if mode != ValidationEnforce && mode != ValidationLog {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op, params := d.find(r.Method, r.URL.Path)
+			if op == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if errs := d.validateRequest(r, op, params); len(errs) > 0 {
+				if mode == ValidationEnforce {
+					problem.Error(w, r, apperrors.Validation("openapi_request_invalid", "the request does not match the API schema", errs))
+					return
+				}
+				meta.LoggerFromContext(r.Context()).WarnContext(r.Context(), "request does not match the OpenAPI schema",
+					"method", r.Method, "path", r.URL.Path, "violations", errs)
+			}
+
+			cw := &capturingWriter{ResponseWriter: w, buffer: mode == ValidationEnforce}
+			next.ServeHTTP(cw, r)
+
+			errs := d.validateResponse(op, cw)
+			if len(errs) == 0 {
+				cw.flush()
+				return
+			}
+
+			if mode == ValidationEnforce {
+				meta.LoggerFromContext(r.Context()).ErrorContext(r.Context(), "response does not match the OpenAPI schema",
+					"method", r.Method, "path", r.URL.Path, "status", cw.status, "violations", errs)
+				problem.Write(w, r, problem.Problem{
+					Status: http.StatusInternalServerError,
+					Code:   "openapi_response_invalid",
+					Detail: "the response does not match the API schema",
+				})
+				return
+			}
+			meta.LoggerFromContext(r.Context()).WarnContext(r.Context(), "response does not match the OpenAPI schema",
+				"method", r.Method, "path", r.URL.Path, "status", cw.status, "violations", errs)
+		})
+	}
+}
+
+// find returns the operation documented for method and path with the path parameters;
+// static segments win over templated ones
+func (d *Document) find(method, path string) (*Operation, map[string]string) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var (
+		best       *Operation
+		bestParams map[string]string
+		bestScore  = -1
+	)
+	for tmpl, item := range d.Paths {
+		op := (*item)[strings.ToLower(method)]
+		if op == nil {
+			continue
+		}
+
+		tmplSegments := strings.Split(strings.Trim(tmpl, "/"), "/")
+		if len(tmplSegments) != len(segments) {
+			continue
+		}
+
+		params := map[string]string{}
+		score := 0
+		for i, seg := range tmplSegments {
+			if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+				params[seg[1:len(seg)-1]] = segments[i]
+				continue
+			}
+			if seg != segments[i] {
+				score = -1
+				break
+			}
+			score++
+		}
+		if score > bestScore {
+			best, bestParams, bestScore = op, params, score
+		}
+	}
+	return best, bestParams
+}
+
+func (d *Document) validateRequest(r *http.Request, op *Operation, pathParams map[string]string) map[string]string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	errs := map[string]string{}
+	query := r.URL.Query()
+
+	for _, p := range op.Parameters {
+		key := p.In + "." + p.Name
+
+		var values []string
+		switch p.In {
+		case "path":
+			if v, ok := pathParams[p.Name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			values = query[p.Name]
+		case "header":
+			values = r.Header.Values(p.Name)
+		}
+
+		if len(values) == 0 {
+			if p.Required {
+				errs[key] = "is required"
+			}
+			continue
+		}
+
+		if p.Schema != nil && p.Schema.Items != nil {
+			items := make([]any, len(values))
+			for i, v := range values {
+				items[i] = d.coerce(p.Schema.Items, v)
+			}
+			d.validateValue(p.Schema, items, key, errs)
+			continue
+		}
+		d.validateValue(p.Schema, d.coerce(p.Schema, values[0]), key, errs)
+	}
+
+	if op.RequestBody != nil {
+		d.validateRequestBody(r, op.RequestBody, errs)
+	}
+	return errs
+}
+
+// validateRequestBody checks a JSON body and puts it back for the handler; malformed
+// JSON is left to the handler's decoder
+func (d *Document) validateRequestBody(r *http.Request, rb *RequestBody, errs map[string]string) {
+	if r.Body == nil || r.Body == http.NoBody {
+		if rb.Required {
+			errs["body"] = "is required"
+		}
+		return
+	}
+
+	media := rb.Content["application/json"]
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if media == nil || !(mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return
+	}
+
+	// Read one byte past the limit to spot oversized bodies; everything read and the
+	// unread rest go back to the handler, whose own limit then rejects it as too large
+	body, err := io.ReadAll(io.LimitReader(r.Body, request.MaxBodyBytes+1))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil || int64(len(body)) > request.MaxBodyBytes {
+		return
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if rb.Required {
+			errs["body"] = "is required"
+		}
+		return
+	}
+
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return
+	}
+	d.validateValue(media.Schema, v, "body", errs)
+}
+
+func (d *Document) validateResponse(op *Operation, cw *capturingWriter) map[string]string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	status := cw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	resp := op.Responses[strconv.Itoa(status)]
+	if resp == nil {
+		resp = op.Responses["default"]
+	}
+	if resp == nil {
+		return map[string]string{"status": fmt.Sprintf("%d is not a documented response", status)}
+	}
+
+	if cw.body.Len() == 0 || len(resp.Content) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(cw.Header().Get("Content-Type"))
+	media := resp.Content[mediaType]
+	if media == nil {
+		// Non-JSON alternatives such as HTML previews are not described by the schema
+		return nil
+	}
+
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(cw.body.Bytes()))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return map[string]string{"body": "is not valid JSON"}
+	}
+
+	errs := map[string]string{}
+	d.validateValue(media.Schema, v, "body", errs)
+	return errs
+}
+
+// capturingWriter records the response for validation. When buffering it holds the
+// response back until flush; otherwise it writes through and keeps a copy.
+type capturingWriter struct {
+	http.ResponseWriter
+	buffer      bool
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (cw *capturingWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.status = code
+	cw.wroteHeader = true
+	if !cw.buffer {
+		cw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (cw *capturingWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	cw.body.Write(b)
+	if cw.buffer {
+		return len(b), nil
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// flush sends a buffered response
+func (cw *capturingWriter) flush() {
+	if !cw.buffer {
+		return
+	}
+	if !cw.wroteHeader {
+		cw.status = http.StatusOK
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	cw.ResponseWriter.Write(cw.body.Bytes())
+}
@@ -0,0 +1,79 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Parameters returns the path, query and header parameters declared by the struct
+// tags of t, as read by request.Bind
+func (d *Document) Parameters(t reflect.Type) []*Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var params []*Parameter
+	d.collectParameters(t, &params)
+	return params
+}
+
+func (d *Document) collectParameters(t reflect.Type, params *[]*Parameter) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			d.collectParameters(sf.Type, params)
+			continue
+		}
+
+		for _, in := range paramTags {
+			name, _, _ := strings.Cut(sf.Tag.Get(in), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+
+			schema := d.schemas.schema(sf.Type)
+			required := applyValidation(schema, sf)
+			*params = append(*params, &Parameter{
+				Name:        name,
+				In:          in,
+				Description: sf.Tag.Get("doc"),
+				Required:    required || in == "path",
+				Schema:      schema,
+			})
+		}
+	}
+}
+
+// HasBody reports whether t has fields decoded from a JSON body
+func HasBody(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() || isParam(sf) || sf.Tag.Get("json") == "-" {
+			continue
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && sf.Tag.Get("json") == "" {
+			if HasBody(sf.Type) {
+				return true
+			}
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12) as used by OpenAPI 3.1
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	rawJSONType  = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// paramTags mark struct fields bound from the request line or headers rather than the body
+var paramTags = []string{"path", "query", "header"}
+
+// schemaRegistry turns Go types into schemas, storing named structs as components
+type schemaRegistry struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaRegistry(components map[string]*Schema) *schemaRegistry {
+	return &schemaRegistry{components: components, names: map[reflect.Type]string{}}
+}
+
+// Schema returns the schema of t; named structs are referenced from components
+func (d *Document) Schema(t reflect.Type) *Schema {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.schemas.schema(t)
+}
+
+func (s *schemaRegistry) schema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "string", Format: "duration"}
+	case t == rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		inner := s.schema(t.Elem())
+		if inner.Ref != "" {
+			return inner
+		}
+		if typ, ok := inner.Type.(string); ok {
+			inner.Type = []string{typ, "null"}
+		}
+		return inner
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		return s.structSchema(t)
+	default:
+		// interfaces and anything else accept any value
+		return &Schema{}
+	}
+}
+
+func (s *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return s.buildStruct(t)
+	}
+
+	if name, ok := s.names[t]; ok {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	name := t.Name()
+	if _, taken := s.components[name]; taken {
+		// Same name in another package
+		name = pkgName(t) + "." + name
+	}
+	s.names[t] = name
+
+	// Register before building so recursive types end in a reference
+	s.components[name] = &Schema{}
+	*s.components[name] = *s.buildStruct(t)
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (s *schemaRegistry) buildStruct(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(schema, t)
+	return schema
+}
+
+func (s *schemaRegistry) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() || isParam(sf) {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		// Embedded structs without a json name are flattened like encoding/json does
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.addFields(schema, ft)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		prop := s.schema(sf.Type)
+		if desc := sf.Tag.Get("doc"); desc != "" {
+			prop = withDescription(prop, desc)
+		}
+		required := applyValidation(prop, sf)
+		schema.Properties[name] = prop
+
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// withDescription returns prop with a description; OpenAPI 3.1 allows siblings of $ref,
+// so a reference gets its own copy next to the $ref instead of an allOf wrapper
+func withDescription(prop *Schema, desc string) *Schema {
+	if prop.Ref != "" {
+		return &Schema{Description: desc, Ref: prop.Ref}
+	}
+	prop.Description = desc
+	return prop
+}
+
+// applyValidation copies the constraints of the validate tag into prop and reports
+// whether the field is required
+func applyValidation(prop *Schema, sf reflect.StructField) bool {
+	required := false
+	for _, rule := range strings.Split(sf.Tag.Get("validate"), ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			prop.Format = "email"
+		case "url", "http_url":
+			prop.Format = "uri"
+		case "uuid", "uuid4":
+			prop.Format = "uuid"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				prop.Enum = append(prop.Enum, v)
+			}
+		case "min", "max", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			setBound(prop, key, n)
+		case "gt", "gte", "lt", "lte":
+			f, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "gt":
+				prop.ExclusiveMinimum = &f
+			case "gte":
+				prop.Minimum = &f
+			case "lt":
+				prop.ExclusiveMaximum = &f
+			case "lte":
+				prop.Maximum = &f
+			}
+		}
+	}
+	return required
+}
+
+func setBound(prop *Schema, key string, n int) {
+	typ, _ := prop.Type.(string)
+	if types, ok := prop.Type.([]string); ok && len(types) > 0 {
+		typ = types[0]
+	}
+
+	f := float64(n)
+	switch typ {
+	case "string":
+		if key == "min" || key == "len" {
+			prop.MinLength = &n
+		}
+		if key == "max" || key == "len" {
+			prop.MaxLength = &n
+		}
+	case "array":
+		if key == "min" || key == "len" {
+			prop.MinItems = &n
+		}
+		if key == "max" || key == "len" {
+			prop.MaxItems = &n
+		}
+	case "integer", "number":
+		if key == "min" || key == "len" {
+			prop.Minimum = &f
+		}
+		if key == "max" || key == "len" {
+			prop.Maximum = &f
+		}
+	}
+}
+
+func isParam(sf reflect.StructField) bool {
+	for _, tag := range paramTags {
+		if sf.Tag.Get(tag) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func pkgName(t reflect.Type) string {
+	path := t.PkgPath()
+	return path[strings.LastIndex(path, "/")+1:]
+}
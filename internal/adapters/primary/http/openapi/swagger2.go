@@ -0,0 +1,124 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MergeSwagger2 converts a Swagger 2.0 spec, such as the output of protoc-gen-openapiv2
+// for the gRPC gateway, and merges its paths and schemas into the served document.
+// Entries already in the document win; merged operations are not validated.
+func (d *Document) MergeSwagger2(spec []byte) error {
+	converted, err := convertSwagger2(spec)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.external = append(d.external, converted)
+	return nil
+}
+
+// convertSwagger2 maps the subset of Swagger 2.0 emitted by protoc-gen-openapiv2 to
+// OpenAPI 3: definitions become component schemas, body parameters become request
+// bodies and response schemas move under application/json
+func convertSwagger2(spec []byte) (map[string]any, error) {
+	spec = bytes.ReplaceAll(spec, []byte(`"#/definitions/`), []byte(`"#/components/schemas/`))
+
+	var in map[string]any
+	if err := json.Unmarshal(spec, &in); err != nil {
+		return nil, fmt.Errorf("invalid swagger spec: %w", err)
+	}
+	if v, _ := in["swagger"].(string); v != "2.0" {
+		return nil, fmt.Errorf("unsupported swagger version %q", v)
+	}
+
+	paths := map[string]any{}
+	inPaths, _ := in["paths"].(map[string]any)
+	for path, rawItem := range inPaths {
+		item, _ := rawItem.(map[string]any)
+		shared, _ := item["parameters"].([]any)
+
+		outItem := map[string]any{}
+		for method, rawOp := range item {
+			op, ok := rawOp.(map[string]any)
+			if !ok || method == "parameters" {
+				continue
+			}
+			outItem[method] = convertOperation(op, shared)
+		}
+		paths[path] = outItem
+	}
+
+	out := map[string]any{
+		"paths":      paths,
+		"components": map[string]any{"schemas": in["definitions"]},
+	}
+	if tags, ok := in["tags"]; ok {
+		out["tags"] = tags
+	}
+	return out, nil
+}
+
+func convertOperation(op map[string]any, shared []any) map[string]any {
+	out := map[string]any{}
+	for k, v := range op {
+		switch k {
+		case "parameters", "responses", "consumes", "produces", "schemes":
+		default:
+			out[k] = v
+		}
+	}
+
+	params, _ := op["parameters"].([]any)
+	var outParams []any
+	for _, raw := range append(shared, params...) {
+		p, _ := raw.(map[string]any)
+		switch p["in"] {
+		case "body":
+			out["requestBody"] = map[string]any{
+				"required": p["required"] == true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": p["schema"]}},
+			}
+		case "path", "query", "header":
+			outParams = append(outParams, convertParameter(p))
+		}
+	}
+	if len(outParams) > 0 {
+		out["parameters"] = outParams
+	}
+
+	responses := map[string]any{}
+	inResponses, _ := op["responses"].(map[string]any)
+	for code, raw := range inResponses {
+		r, _ := raw.(map[string]any)
+		resp := map[string]any{"description": r["description"]}
+		if schema, ok := r["schema"]; ok {
+			resp["content"] = map[string]any{"application/json": map[string]any{"schema": schema}}
+		}
+		responses[code] = resp
+	}
+	out["responses"] = responses
+	return out
+}
+
+func convertParameter(p map[string]any) map[string]any {
+	out := map[string]any{"name": p["name"], "in": p["in"]}
+	if p["in"] == "path" || p["required"] == true {
+		out["required"] = true
+	}
+	if desc, ok := p["description"]; ok {
+		out["description"] = desc
+	}
+
+	schema := map[string]any{}
+	for _, k := range []string{"type", "format", "items", "enum", "default"} {
+		if v, ok := p[k]; ok {
+			schema[k] = v
+		}
+	}
+	out["schema"] = schema
+	return out
+}
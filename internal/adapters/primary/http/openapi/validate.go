@@ -0,0 +1,219 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validateValue checks v, as decoded with json.Decoder.UseNumber, against s and records
+// the first violation per location in errs. The caller holds d.mu.
+func (d *Document) validateValue(s *Schema, v any, path string, errs map[string]string) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		d.validateValue(d.Components.Schemas[name], v, path, errs)
+		return
+	}
+
+	if types := schemaTypes(s); len(types) > 0 && !matchesType(types, v) {
+		errs[path] = "must be of type " + strings.Join(types, " or ")
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		errs[path] = "must be one of: " + joinAny(s.Enum)
+		return
+	}
+
+	switch v := v.(type) {
+	case string:
+		validateString(s, v, path, errs)
+	case json.Number:
+		validateNumber(s, v, path, errs)
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			errs[path] = fmt.Sprintf("must contain at least %d items", *s.MinItems)
+			return
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			errs[path] = fmt.Sprintf("must contain at most %d items", *s.MaxItems)
+			return
+		}
+		for i, item := range v {
+			d.validateValue(s.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				errs[join(path, name)] = "is required"
+			}
+		}
+		for name, val := range v {
+			if prop, ok := s.Properties[name]; ok {
+				d.validateValue(prop, val, join(path, name), errs)
+			} else if s.AdditionalProperties != nil {
+				d.validateValue(s.AdditionalProperties, val, join(path, name), errs)
+			}
+		}
+	}
+}
+
+func validateString(s *Schema, v, path string, errs map[string]string) {
+	n := utf8.RuneCountInString(v)
+	switch {
+	case s.MinLength != nil && n < *s.MinLength:
+		errs[path] = fmt.Sprintf("must be at least %d characters", *s.MinLength)
+		return
+	case s.MaxLength != nil && n > *s.MaxLength:
+		errs[path] = fmt.Sprintf("must be at most %d characters", *s.MaxLength)
+		return
+	}
+
+	var err error
+	switch s.Format {
+	case "email":
+		_, err = mail.ParseAddress(v)
+	case "uri":
+		_, err = url.ParseRequestURI(v)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, v)
+	case "uuid":
+		if !uuidPattern.MatchString(v) {
+			err = fmt.Errorf("invalid uuid")
+		}
+	}
+	if err != nil {
+		errs[path] = "must be a valid " + s.Format
+	}
+}
+
+func validateNumber(s *Schema, v json.Number, path string, errs map[string]string) {
+	f, err := v.Float64()
+	if err != nil {
+		errs[path] = "must be a number"
+		return
+	}
+
+	switch {
+	case s.Minimum != nil && f < *s.Minimum:
+		errs[path] = "must be greater than or equal to " + formatFloat(*s.Minimum)
+	case s.Maximum != nil && f > *s.Maximum:
+		errs[path] = "must be less than or equal to " + formatFloat(*s.Maximum)
+	case s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum:
+		errs[path] = "must be greater than " + formatFloat(*s.ExclusiveMinimum)
+	case s.ExclusiveMaximum != nil && f >= *s.ExclusiveMaximum:
+		errs[path] = "must be less than " + formatFloat(*s.ExclusiveMaximum)
+	}
+}
+
+func schemaTypes(s *Schema) []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	}
+	return nil
+}
+
+func matchesType(types []string, v any) bool {
+	for _, t := range types {
+		switch t {
+		case "null":
+			if v == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := v.(bool); ok {
+				return true
+			}
+		case "string":
+			if _, ok := v.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := v.(json.Number); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := v.(json.Number); ok {
+				if _, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+					return true
+				}
+			}
+		case "array":
+			if _, ok := v.([]any); ok {
+				return true
+			}
+		case "object":
+			if _, ok := v.(map[string]any); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inEnum(enum []any, v any) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// coerce converts a path, query or header value to the JSON type its schema expects,
+// leaving it as a string when it does not parse so validation reports the type
+func (d *Document) coerce(s *Schema, raw string) any {
+	if s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	if s == nil {
+		return raw
+	}
+
+	for _, t := range schemaTypes(s) {
+		switch t {
+		case "integer", "number":
+			if _, err := strconv.ParseFloat(raw, 64); err == nil {
+				return json.Number(raw)
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(raw); err == nil {
+				return b
+			}
+		}
+	}
+	return raw
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func joinAny(values []any) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = fmt.Sprint(v)
+	}
+	return strings.Join(s, ", ")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
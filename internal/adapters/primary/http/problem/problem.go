@@ -0,0 +1,112 @@
+package problem
+
+import (
+	"encoding/json"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/meta"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContentType is the media type of RFC 9457 problem details
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details document with the code, trace ID and
+// validation errors as extension members
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code"`
+	TraceID  string            `json:"trace_id,omitempty"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+// Status returns the HTTP status code for an error kind
+func Status(kind apperrors.Kind) int {
+	switch kind {
+	case apperrors.KindNotFound:
+		return http.StatusNotFound
+	case apperrors.KindBadRequest:
+		return http.StatusBadRequest
+	case apperrors.KindValidation:
+		return http.StatusUnprocessableEntity
+	case apperrors.KindConflict:
+		return http.StatusConflict
+	case apperrors.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperrors.KindForbidden:
+		return http.StatusForbidden
+	case apperrors.KindRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error writes err as a problem response. Errors that are not *apperrors.Error are
+// reported as internal; the internal cause is logged with the request-scoped logger
+// and never sent to the caller.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := apperrors.From(err)
+	status := Status(appErr.Kind)
+
+	level := slog.LevelDebug
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	meta.LoggerFromContext(r.Context()).Log(r.Context(), level, "request failed",
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status", status,
+		"code", appErr.Code,
+		"error", err,
+	)
+
+	if appErr.Kind == apperrors.KindRateLimited && appErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+	}
+
+	Write(w, r, Problem{
+		Status: status,
+		Detail: appErr.Message,
+		Code:   appErr.Code,
+		Errors: appErr.Fields,
+	})
+}
+
+// Write sends p, filling in the defaults for type, title, instance and trace ID
+func Write(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if sc := trace.SpanFromContext(r.Context()).SpanContext(); sc.IsValid() {
+		p.TraceID = sc.TraceID().String()
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// NotFound is a router NotFound handler responding with a problem
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, Problem{Status: http.StatusNotFound, Code: "route_not_found", Detail: "no route matches " + r.URL.Path})
+}
+
+// MethodNotAllowed is a router MethodNotAllowed handler responding with a problem
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, Problem{Status: http.StatusMethodNotAllowed, Code: "method_not_allowed", Detail: r.Method + " is not allowed on " + r.URL.Path})
+}
@@ -0,0 +1,153 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// MaxBodyBytes limits the size of request bodies read by Bind and JSON
+var MaxBodyBytes int64 = 1 << 20
+
+// Bind fills dst, a pointer to a struct, from the request and validates it. Fields
+// tagged `path:"name"`, `query:"name"` and `header:"Name"` are read from the route
+// parameters, query string and headers; the body is decoded as JSON, or as a form into
+// fields tagged `form:"name"`, depending on the Content-Type. Validation rules come from
+// `validate` tags. Errors are *apperrors.Error, ready for problem.Error.
+func Bind(r *http.Request, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return apperrors.Internal(fmt.Errorf("request.Bind: dst must be a pointer to a struct, got %T", dst))
+	}
+
+	if err := decodeBody(r, dst); err != nil {
+		return err
+	}
+
+	fields := map[string]string{}
+	bindValues(v.Elem(), "path", func(name string) []string {
+		if p := chi.URLParam(r, name); p != "" {
+			return []string{p}
+		}
+		return nil
+	}, fields)
+	bindValues(v.Elem(), "query", func(name string) []string { return r.URL.Query()[name] }, fields)
+	bindValues(v.Elem(), "header", func(name string) []string { return r.Header.Values(name) }, fields)
+	if len(fields) > 0 {
+		return apperrors.Validation("invalid_parameters", "one or more parameters are invalid", fields)
+	}
+
+	return Validate(dst)
+}
+
+// JSON decodes the JSON body into dst and validates it
+func JSON(r *http.Request, dst any) error {
+	if err := decodeJSON(r, dst); err != nil {
+		return err
+	}
+	return Validate(dst)
+}
+
+func decodeBody(r *http.Request, dst any) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return decodeJSON(r, dst)
+	case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+		return decodeForm(r, dst)
+	case mediaType == "":
+		return apperrors.BadRequest("missing_content_type", "the request body has no Content-Type")
+	default:
+		return apperrors.BadRequest("unsupported_media_type", "unsupported Content-Type "+mediaType)
+	}
+}
+
+func decodeJSON(r *http.Request, dst any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, MaxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return jsonError(err)
+	}
+	// Reject trailing data such as a second JSON document
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return bodyTooLarge()
+		}
+		return apperrors.BadRequest("malformed_body", "the request body must contain a single JSON value")
+	}
+	return nil
+}
+
+func jsonError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxErr):
+		return bodyTooLarge()
+	case errors.Is(err, io.EOF):
+		return apperrors.BadRequest("empty_body", "the request body must not be empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return apperrors.BadRequest("malformed_body", "the request body contains malformed JSON").Wrap(err)
+	case errors.As(err, &syntaxErr):
+		return apperrors.BadRequest("malformed_body",
+			fmt.Sprintf("the request body contains malformed JSON at offset %d", syntaxErr.Offset)).Wrap(err)
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		return apperrors.Validation("invalid_body", "the request body is invalid",
+			map[string]string{field: "must be of type " + typeErr.Type.String()})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return apperrors.Validation("unknown_field", "the request body contains an unknown field",
+			map[string]string{field: "is not allowed"})
+	default:
+		return apperrors.BadRequest("malformed_body", "the request body could not be decoded").Wrap(err)
+	}
+}
+
+func decodeForm(r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(nil, r.Body, MaxBodyBytes)
+
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		err = r.ParseMultipartForm(MaxBodyBytes)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return bodyTooLarge()
+		}
+		return apperrors.BadRequest("malformed_body", "the request form could not be parsed").Wrap(err)
+	}
+
+	fields := map[string]string{}
+	bindValues(reflect.ValueOf(dst).Elem(), "form", func(name string) []string { return r.PostForm[name] }, fields)
+	if len(fields) > 0 {
+		return apperrors.Validation("invalid_body", "the request body is invalid", fields)
+	}
+	return nil
+}
+
+func bodyTooLarge() error {
+	return apperrors.BadRequest("body_too_large", fmt.Sprintf("the request body must not exceed %d bytes", MaxBodyBytes))
+}
@@ -0,0 +1,100 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate reports field names as they appear on the wire rather than Go field names
+var validate = func() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(sf reflect.StructField) string {
+		for _, tag := range []string{"json", "path", "query", "form", "header"} {
+			name, _, _ := strings.Cut(sf.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return sf.Name
+	})
+	return v
+}()
+
+// Validate checks the `validate` tags of v and returns a validation error listing
+// every failing field
+func Validate(v any) error {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return apperrors.Internal(err)
+	}
+
+	fields := make(map[string]string, len(verrs))
+	for _, fe := range verrs {
+		fields[fieldPath(fe)] = message(fe)
+	}
+	return apperrors.Validation("validation_failed", "the request is invalid", fields)
+}
+
+// fieldPath drops the root struct name from the namespace, e.g. "User.address.city"
+// becomes "address.city"
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, rest, ok := strings.Cut(ns, "."); ok {
+		return rest
+	}
+	return ns
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "min":
+		if isSized(fe.Kind()) {
+			return fmt.Sprintf("must contain at least %s items or characters", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if isSized(fe.Kind()) {
+			return fmt.Sprintf("must contain at most %s items or characters", fe.Param())
+		}
+		return "must be at most " + fe.Param()
+	case "len":
+		return fmt.Sprintf("must have length %s", fe.Param())
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be greater than or equal to " + fe.Param()
+	case "lt":
+		return "must be less than " + fe.Param()
+	case "lte":
+		return "must be less than or equal to " + fe.Param()
+	default:
+		return "failed the " + fe.Tag() + " rule"
+	}
+}
+
+func isSized(k reflect.Kind) bool {
+	return k == reflect.String || k == reflect.Slice || k == reflect.Map || k == reflect.Array
+}
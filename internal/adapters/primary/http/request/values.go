@@ -0,0 +1,117 @@
+package request
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// bindValues sets the fields of v tagged with tag from lookup, recording conversion
+// failures in fields. Embedded structs are walked; fields without values are left as is.
+func bindValues(v reflect.Value, tag string, lookup func(name string) []string, fields map[string]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+		if sf.Anonymous && fv.Kind() == reflect.Struct {
+			bindValues(fv, tag, lookup, fields)
+			continue
+		}
+
+		name, _, _ := strings.Cut(sf.Tag.Get(tag), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		values := lookup(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setValue(fv, values); err != nil {
+			fields[name] = err.Error()
+		}
+	}
+}
+
+func setValue(fv reflect.Value, values []string) error {
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, raw := range values {
+			if err := setScalar(s.Index(i), raw); err != nil {
+				return err
+			}
+		}
+		fv.Set(s)
+		return nil
+	}
+
+	if fv.Kind() == reflect.Pointer {
+		p := reflect.New(fv.Type().Elem())
+		if err := setScalar(p.Elem(), values[0]); err != nil {
+			return err
+		}
+		fv.Set(p)
+		return nil
+	}
+
+	return setScalar(fv, values[0])
+}
+
+func setScalar(fv reflect.Value, raw string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return errMustBe("a duration")
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errMustBe("a boolean")
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return errMustBe("an integer")
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return errMustBe("a non-negative integer")
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, fv.Type().Bits())
+		if err != nil {
+			return errMustBe("a number")
+		}
+		fv.SetFloat(f)
+	default:
+		return errMustBe("a supported type, not " + fv.Type().String())
+	}
+	return nil
+}
+
+type conversionError string
+
+func (e conversionError) Error() string { return string(e) }
+
+func errMustBe(what string) error {
+	return conversionError("must be " + what)
+}
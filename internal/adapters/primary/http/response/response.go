@@ -0,0 +1,35 @@
+package response
+
+import (
+	"encoding/json"
+	"go-chi-boilerplate/internal/meta"
+	"net/http"
+)
+
+// JSON writes v as a JSON response with the given status
+func JSON[T any](w http.ResponseWriter, r *http.Request, status int, v T) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		meta.LoggerFromContext(r.Context()).WarnContext(r.Context(), "failed to write response", "error", err)
+	}
+}
+
+// OK writes v with 200 OK
+func OK[T any](w http.ResponseWriter, r *http.Request, v T) {
+	JSON(w, r, http.StatusOK, v)
+}
+
+// Created writes v with 201 Created and, when set, the Location header
+func Created[T any](w http.ResponseWriter, r *http.Request, location string, v T) {
+	if location != "" {
+		w.Header().Set("Location", location)
+	}
+	JSON(w, r, http.StatusCreated, v)
+}
+
+// NoContent writes 204 No Content
+func NoContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,332 @@
+package router_test
+
+import (
+	"encoding/json"
+	"go-chi-boilerplate/internal/adapters/primary/http/router"
+	"go-chi-boilerplate/internal/adapters/primary/http/routes"
+	"go-chi-boilerplate/internal/adapters/primary/http/session"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/oidc"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/oidc/oidctest"
+	"go-chi-boilerplate/internal/config"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sessionCookie = "session"
+
+// app is the service signed in to an oidctest provider, driven by a browser that
+// keeps cookies but follows no redirects, so every hop can be inspected
+type app struct {
+	provider *oidctest.Provider
+	server   *httptest.Server
+	browser  *http.Client
+}
+
+func newApp(t *testing.T) *app {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	provider := oidctest.NewProvider(t)
+
+	var handler http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client := oidc.New(&config.OIDCConfigs{
+		IssuerURL:             provider.URL,
+		ClientID:              oidctest.ClientID,
+		ClientSecret:          oidctest.ClientSecret,
+		RedirectURL:           server.URL + "/auth/callback",
+		Scopes:                []string{"openid", "email"},
+		PostLogoutRedirectURL: server.URL + "/",
+	}, logger)
+
+	secret := strings.Repeat("s", 32)
+	cookies, err := session.NewCookies(secret, session.CookieOptions{SameSite: http.SameSiteLaxMode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, _ = router.SetupRouter(&config.ServerConfigs{ServiceName: "test"}, &config.SystemConfigs{}, logger, routes.Dependencies{
+		OIDC:     client,
+		Cookies:  cookies,
+		Sessions: session.NewCookieStore(cookies, sessionCookie, time.Hour),
+	})
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	browser := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return &app{provider: provider, server: server, browser: browser}
+}
+
+// do sends a request with the browser's cookies; target may be a path of the app
+func (a *app) do(t *testing.T, method, target string, header http.Header) *http.Response {
+	t.Helper()
+
+	if strings.HasPrefix(target, "/") {
+		target = a.server.URL + target
+	}
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := a.browser.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// startLogin calls /auth/login and returns the authorization URL it redirects to
+func (a *app) startLogin(t *testing.T, returnTo string) *url.URL {
+	t.Helper()
+
+	resp := a.do(t, http.MethodGet, "/auth/login?return_to="+url.QueryEscape(returnTo), nil)
+	authURL := location(t, resp, http.StatusFound)
+	if !strings.HasPrefix(authURL.String(), a.provider.URL+"/authorize") {
+		t.Fatalf("login redirected to %s, want the provider", authURL)
+	}
+	return authURL
+}
+
+// authorize sends the browser to the provider and returns the callback URL it
+// redirects back to
+func (a *app) authorize(t *testing.T, authURL *url.URL) *url.URL {
+	t.Helper()
+
+	callback := location(t, a.do(t, http.MethodGet, authURL.String(), nil), http.StatusFound)
+	if !strings.HasPrefix(callback.String(), a.server.URL+"/auth/callback") {
+		t.Fatalf("provider redirected to %s, want the callback", callback)
+	}
+	return callback
+}
+
+// login signs the browser in and returns where the callback redirected to
+func (a *app) login(t *testing.T, returnTo string) *url.URL {
+	t.Helper()
+
+	callback := a.authorize(t, a.startLogin(t, returnTo))
+	return location(t, a.do(t, http.MethodGet, callback.String(), nil), http.StatusFound)
+}
+
+// me calls /api/v2/me and returns the status and the subject of the response
+func (a *app) me(t *testing.T) (int, string) {
+	t.Helper()
+
+	resp := a.do(t, http.MethodGet, "/api/v2/me", http.Header{"Accept": {"application/json"}})
+	var body struct {
+		Subject string `json:"subject"`
+		Method  string `json:"method"`
+	}
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Method != "oidc" {
+			t.Fatalf("method = %q, want oidc", body.Method)
+		}
+	}
+	return resp.StatusCode, body.Subject
+}
+
+// sessionCookie returns the browser's current session cookie
+func (a *app) sessionCookie(t *testing.T) *http.Cookie {
+	t.Helper()
+
+	u, _ := url.Parse(a.server.URL)
+	for _, c := range a.browser.Jar.Cookies(u) {
+		if c.Name == sessionCookie {
+			return c
+		}
+	}
+	t.Fatal("browser has no session cookie")
+	return nil
+}
+
+func location(t *testing.T, resp *http.Response, status int) *url.URL {
+	t.Helper()
+
+	if resp.StatusCode != status {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("%s %s responded %d, want %d: %s", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, status, body)
+	}
+	// Not resp.Location: it would resolve relative redirects against the request
+	u, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// assertLoginFailed completes a login with callback and expects it to be refused
+func assertLoginFailed(t *testing.T, a *app, callback *url.URL) {
+	t.Helper()
+
+	resp := a.do(t, http.MethodGet, callback.String(), nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("callback responded %d, want 401", resp.StatusCode)
+	}
+	if status, _ := a.me(t); status != http.StatusUnauthorized {
+		t.Fatalf("/api/v2/me responded %d after a failed login, want 401", status)
+	}
+}
+
+func TestOIDCLoginAndLogout(t *testing.T) {
+	a := newApp(t)
+
+	if status, _ := a.me(t); status != http.StatusUnauthorized {
+		t.Fatalf("/api/v2/me responded %d before signing in, want 401", status)
+	}
+
+	if target := a.login(t, "/api/v2/me?x=1"); target.String() != "/api/v2/me?x=1" {
+		t.Fatalf("login returned to %s", target)
+	}
+	if status, subject := a.me(t); status != http.StatusOK || subject != "user-1" {
+		t.Fatalf("/api/v2/me responded %d for %q, want 200 for user-1", status, subject)
+	}
+
+	// Signing out needs the CSRF token of the session
+	if resp := a.do(t, http.MethodPost, "/auth/logout", nil); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("logout without CSRF token responded %d, want 403", resp.StatusCode)
+	}
+	var csrf struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(a.do(t, http.MethodGet, "/auth/csrf", nil).Body).Decode(&csrf); err != nil {
+		t.Fatal(err)
+	}
+
+	endSession := location(t, a.do(t, http.MethodPost, "/auth/logout", http.Header{"X-CSRF-Token": {csrf.Token}}), http.StatusSeeOther)
+	if !strings.HasPrefix(endSession.String(), a.provider.URL+"/logout") || endSession.Query().Get("id_token_hint") == "" {
+		t.Fatalf("logout redirected to %s, want the provider's end session endpoint with an id_token_hint", endSession)
+	}
+	back := location(t, a.do(t, http.MethodGet, endSession.String(), nil), http.StatusFound)
+	if back.String() != a.server.URL+"/" {
+		t.Fatalf("provider logout redirected to %s", back)
+	}
+	if got := a.provider.Logouts(); got != 1 {
+		t.Fatalf("provider logouts = %d, want 1", got)
+	}
+	if status, _ := a.me(t); status != http.StatusUnauthorized {
+		t.Fatalf("/api/v2/me responded %d after signing out, want 401", status)
+	}
+}
+
+func TestOIDCReturnToStaysLocal(t *testing.T) {
+	for _, returnTo := range []string{"//evil.example/path", `/\evil.example/path`, "/%2F/evil.example"} {
+		t.Run(returnTo, func(t *testing.T) {
+			a := newApp(t)
+			target := a.login(t, returnTo)
+			if target.Host != "" || strings.HasPrefix(target.String(), "//") || strings.HasPrefix(target.String(), `/\`) {
+				t.Fatalf("login returned to %s, want a local path", target)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackRejectsStateMismatch(t *testing.T) {
+	a := newApp(t)
+
+	callback := a.authorize(t, a.startLogin(t, "/"))
+	q := callback.Query()
+	q.Set("state", "forged")
+	callback.RawQuery = q.Encode()
+	assertLoginFailed(t, a, callback)
+}
+
+func TestOIDCCallbackRejectsNonceMismatch(t *testing.T) {
+	a := newApp(t)
+
+	// The provider puts the nonce of the authorization request into the ID token
+	authURL := a.startLogin(t, "/")
+	q := authURL.Query()
+	q.Set("nonce", "replayed")
+	authURL.RawQuery = q.Encode()
+	assertLoginFailed(t, a, a.authorize(t, authURL))
+}
+
+func TestOIDCCallbackChecksPKCE(t *testing.T) {
+	a := newApp(t)
+
+	// A code obtained with another challenge can't be redeemed with this login's verifier
+	authURL := a.startLogin(t, "/")
+	q := authURL.Query()
+	q.Set("code_challenge", oidc.Challenge("attacker-verifier"))
+	authURL.RawQuery = q.Encode()
+	assertLoginFailed(t, a, a.authorize(t, authURL))
+}
+
+func TestOIDCCallbackRejectsReplayedCode(t *testing.T) {
+	a := newApp(t)
+
+	callback := a.authorize(t, a.startLogin(t, "/"))
+	location(t, a.do(t, http.MethodGet, callback.String(), nil), http.StatusFound)
+
+	// A second login in the same browser can't redeem the used code
+	a.startLogin(t, "/")
+	resp := a.do(t, http.MethodGet, callback.String(), nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("replayed callback responded %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestOIDCSessionRefresh(t *testing.T) {
+	a := newApp(t)
+	// Tokens expiring within a second are always due for a refresh
+	a.provider.SetAccessTokenTTL(time.Second)
+	a.login(t, "/")
+	stale := a.sessionCookie(t)
+
+	for i := 1; i <= 2; i++ {
+		if status, subject := a.me(t); status != http.StatusOK || subject != "user-1" {
+			t.Fatalf("/api/v2/me responded %d for %q, want 200 for user-1", status, subject)
+		}
+		// Every refresh rotates the refresh token stored in the session
+		if got := a.provider.Refreshes(); got != i {
+			t.Fatalf("provider refreshes = %d, want %d", got, i)
+		}
+	}
+
+	replay := func() int {
+		req, err := http.NewRequest(http.MethodGet, a.server.URL+"/api/v2/me", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(stale)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// A request racing a refresh sends the rotated-out refresh token; the session
+	// lasts until its access token expires
+	if status := replay(); status != http.StatusOK {
+		t.Fatalf("session with a rotated refresh token responded %d, want 200", status)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if status := replay(); status != http.StatusUnauthorized {
+		t.Fatalf("expired session with a rotated refresh token responded %d, want 401", status)
+	}
+}
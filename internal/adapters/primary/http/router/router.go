@@ -2,29 +2,57 @@ package router
 
 import (
 	custom "go-chi-boilerplate/internal/adapters/primary/http/middleware"
+	"go-chi-boilerplate/internal/adapters/primary/http/openapi"
+	"go-chi-boilerplate/internal/adapters/primary/http/problem"
 	"go-chi-boilerplate/internal/adapters/primary/http/routes"
-	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/meta"
 	"log/slog"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
-func SetupRouter(serviceName string, logger *slog.Logger, db *postgresql.PostgresDB) *chi.Mux {
+// SetupRouter builds the main router and, when ADMIN_PORT is set, the admin router that
+// serves the /system endpoints; admin is nil otherwise and the main router serves them
+func SetupRouter(cfg *config.ServerConfigs, sys *config.SystemConfigs, logger *slog.Logger, deps routes.Dependencies) (main, admin *chi.Mux) {
+	// Typed endpoints document themselves here as they are registered
+	doc := openapi.New(cfg.ServiceName+" API", meta.Version())
+	for i, spec := range deps.GatewaySpecs {
+		if err := doc.MergeSwagger2(spec); err != nil {
+			logger.Warn("failed to merge grpc gateway OpenAPI spec", "index", i, "error", err)
+		}
+	}
+
+	main = newRouter(cfg, logger, doc)
+	if sys.AdminPort == "" {
+		routes.AddSystemRoutes(main, cfg, sys, deps, doc)
+	} else {
+		// Probes stay on the main port so orchestrators don't need the admin listener
+		routes.AddProbeRoutes(main, deps, doc)
+
+		admin = newRouter(cfg, logger, doc)
+		routes.AddSystemRoutes(admin, cfg, sys, deps, doc)
+	}
+	routes.AddAuthRoutes(main, logger, deps, doc)
+	routes.AddApiRoutes(main, logger, deps, doc)
+
+	return main, admin
+}
+
+// newRouter creates a router with the shared middleware stack
+func newRouter(cfg *config.ServerConfigs, logger *slog.Logger, doc *openapi.Document) *chi.Mux {
 	r := chi.NewRouter()
 
-	r.Use(otelhttp.NewMiddleware(serviceName))
+	r.Use(otelhttp.NewMiddleware(cfg.ServiceName))
+	r.Use(custom.ProfileLabels)
 	r.Use(custom.LoggingMiddleware(logger))
-	r.Use(middleware.Recoverer)
+	r.Use(custom.Recoverer)
 	r.Use(custom.MetricsMiddleware)
+	r.Use(doc.Validator(openapi.ValidationMode(cfg.OpenAPIValidation)))
 
-	registerRoutes(r, db)
+	r.NotFound(problem.NotFound)
+	r.MethodNotAllowed(problem.MethodNotAllowed)
 	return r
 }
-
-func registerRoutes(r chi.Router, db *postgresql.PostgresDB) {
-	routes.AddSystemRoutes(r, db)
-	routes.AddApiRoutes(r)
-}
@@ -1,15 +1,174 @@
 package routes
 
 import (
+	"go-chi-boilerplate/internal/adapters/primary/http/endpoint"
 	"go-chi-boilerplate/internal/adapters/primary/http/handlers"
+	custom "go-chi-boilerplate/internal/adapters/primary/http/middleware"
+	"go-chi-boilerplate/internal/adapters/primary/http/openapi"
+	"log/slog"
+	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
 
-func AddApiRoutes(rg chi.Router) {
+// apiVersion is a route group mounted under /api/<name>
+type apiVersion struct {
+	name string
+	// deprecation, when set, adds Deprecation/Sunset headers to every route of the version
+	deprecation *custom.Deprecation
+	routes      func(g *endpoint.Group, logger *slog.Logger, deps Dependencies)
+}
+
+// bearerScheme and apiKeyScheme are the OpenAPI security schemes of routes behind
+// custom.Authenticate and custom.AuthenticateAPIKey
+const (
+	bearerScheme = "bearerAuth"
+	apiKeyScheme = "apiKeyAuth"
+)
+
+// meAction and gatewayAction are the policy actions of /api/v2/me and the gRPC gateway
+const (
+	meAction      = "me:read"
+	gatewayAction = "gateway:call"
+)
+
+// apiVersions lists the mounted versions, oldest first
+var apiVersions = []apiVersion{
+	{name: "v1", routes: addV1Routes},
+	{name: "v2", routes: addV2Routes},
+}
+
+func AddApiRoutes(rg chi.Router, logger *slog.Logger, deps Dependencies, doc *openapi.Document) {
 	api := chi.NewRouter()
 
-	api.Get("/version", handlers.APIVersion)
+	supported := make([]string, 0, len(apiVersions))
+	for _, v := range apiVersions {
+		supported = append(supported, v.name)
+	}
+	api.Use(custom.NegotiateVersion(supported...))
+
+	if deps.Auth != nil {
+		doc.AddSecurityScheme(bearerScheme, &openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"})
+	}
+	if deps.APIKeys != nil {
+		doc.AddSecurityScheme(apiKeyScheme, &openapi.SecurityScheme{
+			Type:        "apiKey",
+			In:          "header",
+			Name:        custom.APIKeyHeader,
+			Description: "Also accepted as Authorization: ApiKey <key>",
+		})
+	}
+
+	// Unversioned alias kept for existing clients
+	unversioned := endpoint.NewGroup(api, doc, "/api")
+	unversioned.Tags = []string{"api"}
+	endpoint.Get(unversioned.With(limited(deps, nil, versionCache(deps, logger))...), "/version", handlers.GetVersion, endpoint.Operation{
+		ID:      "getVersion",
+		Summary: "Get the build metadata of the running service",
+	})
+
+	for _, v := range apiVersions {
+		api.Route("/"+v.name, func(r chi.Router) {
+			r.Use(custom.VersionHeader(v.name))
+			if v.deprecation != nil {
+				r.Use(custom.Deprecated(v.name, *v.deprecation))
+			}
+
+			g := endpoint.NewGroup(r, doc, "/api/"+v.name)
+			g.Tags = []string{"api " + v.name}
+			g.Deprecated = v.deprecation != nil
+			v.routes(g, logger, deps)
+		})
+	}
+
+	// Anything not routed above is transcoded to the gRPC services. The caller must be
+	// authenticated when an authenticator is configured, and with a policy also granted
+	// the gateway action; the resource's "*" attribute is the path below /api.
+	if deps.Gateway != nil {
+		authn, _ := authenticated(deps, logger)
+		api.With(limited(deps, authn, authorized(deps, authn, gatewayAction)...)...).Handle("/*", deps.Gateway)
+	}
 
 	rg.Mount("/api", api)
 }
+
+func addV1Routes(g *endpoint.Group, logger *slog.Logger, deps Dependencies) {
+	endpoint.Get(g.With(limited(deps, nil, versionCache(deps, logger))...), "/version", handlers.GetVersionV1, endpoint.Operation{
+		ID:      "getVersionV1",
+		Summary: "Get the version of the running service",
+	})
+}
+
+func addV2Routes(g *endpoint.Group, logger *slog.Logger, deps Dependencies) {
+	endpoint.Get(g.With(limited(deps, nil, versionCache(deps, logger))...), "/version", handlers.GetVersion, endpoint.Operation{
+		ID:      "getVersionV2",
+		Summary: "Get the build metadata of the running service",
+	})
+
+	if authn, security := authenticated(deps, logger); authn != nil {
+		endpoint.Get(g.With(limited(deps, authn, authorized(deps, authn, meAction)...)...), "/me", handlers.GetMe, endpoint.Operation{
+			ID:       "getMe",
+			Summary:  "Get the authenticated caller",
+			Security: security,
+		})
+	}
+}
+
+// authenticated returns the middlewares that require a bearer token, an API key or a
+// signed-in browser session, whichever are configured, and the matching OpenAPI
+// security requirements; both are nil when none is
+func authenticated(deps Dependencies, logger *slog.Logger) ([]func(http.Handler) http.Handler, []map[string][]string) {
+	var authn []func(http.Handler) http.Handler
+	var security []map[string][]string
+
+	if deps.OIDC != nil {
+		authn = append(authn, custom.LoadUser(deps.OIDC, deps.Sessions, logger))
+	}
+	if deps.APIKeys != nil {
+		authn = append(authn, custom.AuthenticateAPIKeyOptional(deps.APIKeys))
+		security = append(security, map[string][]string{apiKeyScheme: {}})
+	}
+	switch {
+	case deps.Auth != nil:
+		// Authenticate keeps the principal of a session or API key
+		authn = append(authn, custom.Authenticate(deps.Auth))
+		security = append(security, map[string][]string{bearerScheme: {}})
+	case authn != nil:
+		authn = append(authn, custom.RequirePrincipal)
+	}
+	if deps.OIDC != nil {
+		// Last, once it is known whether the session cookie authenticated the request
+		authn = append(authn, custom.CSRF(deps.Cookies, deps.Sessions))
+	}
+	return authn, security
+}
+
+// authorized returns the middleware that asks the policy whether the principal
+// authenticated by authn may perform action; none when there is no policy or no authn
+func authorized(deps Dependencies, authn []func(http.Handler) http.Handler, action string) []func(http.Handler) http.Handler {
+	if deps.Policy == nil || authn == nil {
+		return nil
+	}
+	return []func(http.Handler) http.Handler{custom.Authorize(deps.Policy, action)}
+}
+
+// limited chains authn, the /api rate limit when one is configured, and mws. Failed
+// authentications are limited before authn, so credentials can't be brute-forced;
+// the request limit comes after it so it can count per user, and before response
+// caches so cached responses count too.
+func limited(deps Dependencies, authn []func(http.Handler) http.Handler, mws ...func(http.Handler) http.Handler) []func(http.Handler) http.Handler {
+	var chain []func(http.Handler) http.Handler
+	if len(authn) > 0 && deps.AuthRateLimit != nil {
+		chain = append(chain, deps.AuthRateLimit)
+	}
+	chain = append(chain, authn...)
+	if deps.RateLimit != nil {
+		chain = append(chain, deps.RateLimit)
+	}
+	return append(chain, mws...)
+}
+
+func versionCache(deps Dependencies, logger *slog.Logger) func(next http.Handler) http.Handler {
+	return custom.ResponseCache(deps.Cache, logger, custom.CacheOptions{TTL: time.Minute})
+}
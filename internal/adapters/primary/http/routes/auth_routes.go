@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"go-chi-boilerplate/internal/adapters/primary/http/endpoint"
+	"go-chi-boilerplate/internal/adapters/primary/http/handlers"
+	custom "go-chi-boilerplate/internal/adapters/primary/http/middleware"
+	"go-chi-boilerplate/internal/adapters/primary/http/openapi"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// LoginPath starts the OIDC login; see custom.RequireUser
+const LoginPath = "/auth/login"
+
+// AddAuthRoutes serves the OIDC login flow under /auth; nothing is added when OIDC
+// is not configured
+func AddAuthRoutes(rg chi.Router, logger *slog.Logger, deps Dependencies, doc *openapi.Document) {
+	if deps.OIDC == nil {
+		return
+	}
+
+	rg.Route("/auth", func(r chi.Router) {
+		g := endpoint.NewGroup(r, doc, "/auth")
+		g.Tags = []string{"auth"}
+
+		endpoint.Raw[handlers.LoginRequest, endpoint.Empty](g, http.MethodGet, "/login", handlers.OIDCLogin(deps.OIDC, deps.Cookies), endpoint.Operation{
+			ID:          "login",
+			Summary:     "Sign in with the identity provider",
+			Description: "Redirects to the identity provider using the authorization-code flow with PKCE.",
+			Status:      http.StatusFound,
+		})
+		endpoint.Raw[handlers.CallbackRequest, endpoint.Empty](g, http.MethodGet, "/callback", handlers.OIDCCallback(deps.OIDC, deps.Cookies, deps.Sessions, logger), endpoint.Operation{
+			ID:          "loginCallback",
+			Summary:     "Complete a sign-in",
+			Description: "The identity provider redirects here; starts the session and redirects to return_to of the login.",
+			Status:      http.StatusFound,
+		})
+
+		// Signing out is a state change made with the session cookie, so it needs the
+		// CSRF token like any other
+		session := g.With(custom.LoadUser(deps.OIDC, deps.Sessions, logger), custom.CSRF(deps.Cookies, deps.Sessions))
+		endpoint.Get(session, "/csrf", handlers.GetCSRFToken(custom.CSRFToken), endpoint.Operation{
+			ID:          "getCSRFToken",
+			Summary:     "Get the CSRF token",
+			Description: "Returns the token unsafe requests authenticated by the session cookie must send as X-CSRF-Token or the csrf_token form field.",
+		})
+		endpoint.Raw[endpoint.Empty, endpoint.Empty](session, http.MethodPost, "/logout", handlers.OIDCLogout(deps.OIDC, deps.Sessions), endpoint.Operation{
+			ID:          "logout",
+			Summary:     "Sign out",
+			Description: "Ends the session and redirects to the identity provider's logout when it supports one. Requires the CSRF token when signed in.",
+			Status:      http.StatusSeeOther,
+		})
+	})
+}
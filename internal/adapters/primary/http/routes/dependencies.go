@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"go-chi-boilerplate/internal/adapters/primary/http/session"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/oidc"
+	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql"
+	"go-chi-boilerplate/internal/adapters/secondary/external/email/templates"
+	"go-chi-boilerplate/internal/core/health"
+	"go-chi-boilerplate/internal/core/ports"
+	"net/http"
+)
+
+// Dependencies groups the adapters the HTTP routes are built from; optional ones may be nil
+type Dependencies struct {
+	DB            *postgresql.PostgresDB
+	Health        *health.Checker
+	Cache         ports.Cache
+	EmailRenderer *templates.Renderer
+	// Auth verifies bearer tokens; routes that need a principal are skipped when nil
+	Auth ports.TokenVerifier
+	// APIKeys verifies API keys of machine clients; nil when API_KEYS_ENABLED is off
+	APIKeys ports.TokenVerifier
+	// OIDC signs browser users in under /auth; nil when OIDC_ISSUER_URL is not set
+	OIDC *oidc.Client
+	// Cookies seals short-lived state such as a login in progress and derives CSRF tokens
+	Cookies *session.Cookies
+	// Sessions keeps the sessions of signed-in browser users
+	Sessions session.Store
+	// Policy decides what authenticated callers may do, see middleware.Authorize; nil
+	// when AUTH_POLICY_FILE is not set
+	Policy ports.PolicyEngine
+	// RateLimit throttles the /api routes, see middleware.RateLimit; nil when
+	// RATE_LIMIT_ENABLED is off
+	RateLimit func(http.Handler) http.Handler
+	// AuthRateLimit throttles failed authentication attempts, see
+	// middleware.LimitFailedAuth; nil when RATE_LIMIT_ENABLED is off
+	AuthRateLimit func(http.Handler) http.Handler
+	// Gateway serves gRPC services over REST/JSON under /api
+	Gateway http.Handler
+	// GatewaySpecs are the Swagger 2.0 specs of the gateway services, merged into /system/openapi.json
+	GatewaySpecs [][]byte
+}
@@ -1,24 +1,144 @@
 package routes
 
 import (
+	"expvar"
+	"go-chi-boilerplate/internal/adapters/primary/http/endpoint"
 	"go-chi-boilerplate/internal/adapters/primary/http/handlers"
-	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql"
+	custom "go-chi-boilerplate/internal/adapters/primary/http/middleware"
+	"go-chi-boilerplate/internal/adapters/primary/http/openapi"
+	"go-chi-boilerplate/internal/adapters/secondary/external/email/templates"
+	"go-chi-boilerplate/internal/config"
+	"net/http"
+	"net/http/pprof"
 
 	"github.com/go-chi/chi/v5"
-
-	_ "go-chi-boilerplate/docs"
 )
 
-func AddSystemRoutes(r chi.Router, db *postgresql.PostgresDB) {
+// AddSystemRoutes mounts the /system endpoints. Health, liveness and readiness are
+// always served without protection; the other endpoints are only registered when
+// enabled in SYSTEM_ENDPOINTS and sit behind the configured access checks.
+func AddSystemRoutes(r chi.Router, cfg *config.ServerConfigs, sys *config.SystemConfigs, deps Dependencies, doc *openapi.Document) {
 	system := chi.NewRouter()
+	addProbeRoutes(system, deps, doc)
 
-	system.Get("/health", handlers.Health)
-	system.Get("/liveness", handlers.Liveness)
-	system.Get("/readiness", handlers.Readiness(db))
-
-	system.Handle("/metrics", handlers.MetricsHandler())
+	allowed, _ := sys.AllowedPrefixes()
+	system.Group(func(protected chi.Router) {
+		protected.Use(custom.RequireAccess(custom.AccessOptions{
+			Realm:       cfg.ServiceName + " system",
+			Username:    sys.AuthUsername,
+			Password:    sys.AuthPassword,
+			Token:       sys.AuthToken,
+			AllowedNets: allowed,
+		}))
+		addProtectedSystemRoutes(protected, cfg, sys, deps, doc)
+	})
 
-	system.Handle("/swagger/*", handlers.SwaggerHandler())
+	r.Mount("/system", system)
+}
 
+// AddProbeRoutes mounts only the health, liveness and readiness endpoints under
+// /system; it is used on the main port when the system endpoints have their own listener
+func AddProbeRoutes(r chi.Router, deps Dependencies, doc *openapi.Document) {
+	system := chi.NewRouter()
+	addProbeRoutes(system, deps, doc)
 	r.Mount("/system", system)
 }
+
+func addProbeRoutes(r chi.Router, deps Dependencies, doc *openapi.Document) {
+	g := endpoint.NewGroup(r, doc, "/system")
+	g.Tags = []string{"system"}
+
+	endpoint.Get(g, "/health", handlers.Health, endpoint.Operation{
+		ID:      "getHealth",
+		Summary: "Show the health status",
+	})
+	endpoint.Get(g, "/liveness", handlers.Liveness, endpoint.Operation{
+		ID:      "getLiveness",
+		Summary: "Liveness probe for Kubernetes",
+	})
+	endpoint.Raw[endpoint.Empty, handlers.ReadinessResponse](g, http.MethodGet, "/readiness", handlers.Readiness(deps.Health), endpoint.Operation{
+		ID:          "getReadiness",
+		Summary:     "Readiness probe for Kubernetes",
+		Description: "Runs the registered dependency checks; responds 503 with the same body when one fails.",
+	})
+}
+
+func addProtectedSystemRoutes(r chi.Router, cfg *config.ServerConfigs, sys *config.SystemConfigs, deps Dependencies, doc *openapi.Document) {
+	g := endpoint.NewGroup(r, doc, "/system")
+	g.Tags = []string{"system"}
+	security := systemSecurity(sys, doc)
+
+	if sys.Enabled("info") {
+		endpoint.Get(g, "/info", handlers.SystemInfo(cfg), endpoint.Operation{
+			ID:       "getSystemInfo",
+			Summary:  "Show the service name, environment, uptime and build metadata",
+			Security: security,
+		})
+	}
+
+	if sys.Enabled("metrics") {
+		r.Handle("/metrics", handlers.MetricsHandler())
+	}
+
+	if sys.Enabled("openapi") {
+		r.Get("/openapi.json", doc.Handler())
+	}
+	if sys.Enabled("swagger") {
+		r.Get("/swagger/*", handlers.SwaggerUI("/system/openapi.json"))
+	}
+
+	if sys.Enabled("debug") {
+		addDebugRoutes(r, g, cfg, security)
+	}
+
+	// Email previews render sample data; SYSTEM_ENDPOINTS enables them in development only by default
+	if sys.Enabled("email_preview") && deps.EmailRenderer != nil {
+		endpoint.Raw[endpoint.Empty, handlers.EmailTemplatesResponse](g, http.MethodGet, "/email/preview", handlers.EmailTemplates(deps.EmailRenderer), endpoint.Operation{
+			ID:       "listEmailTemplates",
+			Summary:  "List the email templates available for preview",
+			Security: security,
+		})
+		endpoint.Raw[handlers.EmailPreviewRequest, templates.Rendered](g, http.MethodGet, "/email/preview/{name}", handlers.EmailPreview(deps.EmailRenderer), endpoint.Operation{
+			ID:          "previewEmail",
+			Summary:     "Render an email template with its sample data",
+			Description: "Responds with HTML by default, plain text with format=text or JSON with format=json.",
+			Security:    security,
+		})
+	}
+}
+
+// systemSecurity registers the schemes accepted by the protected system endpoints and
+// returns the matching security requirement; nil when no credentials are configured
+func systemSecurity(sys *config.SystemConfigs, doc *openapi.Document) []map[string][]string {
+	var security []map[string][]string
+	if sys.AuthUsername != "" {
+		doc.AddSecurityScheme("systemBasic", &openapi.SecurityScheme{Type: "http", Scheme: "basic"})
+		security = append(security, map[string][]string{"systemBasic": {}})
+	}
+	if sys.AuthToken != "" {
+		doc.AddSecurityScheme("systemBearer", &openapi.SecurityScheme{Type: "http", Scheme: "bearer"})
+		security = append(security, map[string][]string{"systemBearer": {}})
+	}
+	return security
+}
+
+// addDebugRoutes mounts pprof, expvar and the runtime diagnostics under /system/debug
+func addDebugRoutes(r chi.Router, g *endpoint.Group, cfg *config.ServerConfigs, security []map[string][]string) {
+	r.Get("/debug/pprof/", handlers.PprofIndex)
+	r.Get("/debug/pprof/cmdline", pprof.Cmdline)
+	r.Get("/debug/pprof/profile", pprof.Profile)
+	r.Get("/debug/pprof/symbol", pprof.Symbol)
+	r.Post("/debug/pprof/symbol", pprof.Symbol)
+	r.Get("/debug/pprof/trace", pprof.Trace)
+	r.Get("/debug/pprof/{name}", handlers.PprofProfile)
+	r.Handle("/debug/vars", expvar.Handler())
+
+	r.Get("/debug/goroutines", handlers.Goroutines)
+	r.Get("/debug/trace", handlers.Trace(cfg.ServiceName))
+	r.Get("/debug/heap", handlers.HeapSnapshot(cfg.ServiceName))
+	endpoint.Get(g, "/debug/gc", handlers.GCStats, endpoint.Operation{
+		ID:       "getGCStats",
+		Summary:  "Show garbage collector and heap statistics",
+		Security: security,
+	})
+}
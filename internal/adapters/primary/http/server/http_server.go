@@ -3,12 +3,10 @@ package server
 import (
 	"context"
 	"go-chi-boilerplate/internal/adapters/primary/http/router"
-	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql"
+	"go-chi-boilerplate/internal/adapters/primary/http/routes"
 	"go-chi-boilerplate/internal/config"
 	"log/slog"
 	"net/http"
-	"os"
-	"os/signal"
 	"time"
 )
 
@@ -16,13 +14,15 @@ type Server struct {
 	cfg    *config.ServerConfigs
 	logger *slog.Logger
 	http   *http.Server
+	// admin serves the /system endpoints when ADMIN_PORT is set
+	admin *http.Server
 }
 
 // New creates a Server with all dependencies injected
-func New(cfg *config.ServerConfigs, logger *slog.Logger, db *postgresql.PostgresDB) *Server {
-	r := router.SetupRouter(cfg.ServiceName, logger, db)
+func New(cfg *config.ServerConfigs, sys *config.SystemConfigs, logger *slog.Logger, deps routes.Dependencies) *Server {
+	r, admin := router.SetupRouter(cfg, sys, logger, deps)
 
-	return &Server{
+	s := &Server{
 		cfg:    cfg,
 		logger: logger,
 		http: &http.Server{
@@ -30,29 +30,43 @@ func New(cfg *config.ServerConfigs, logger *slog.Logger, db *postgresql.Postgres
 			Handler: r,
 		},
 	}
+	if admin != nil {
+		s.admin = &http.Server{
+			Addr:    sys.AdminPort,
+			Handler: admin,
+		}
+	}
+	return s
 }
 
-// Run starts the HTTP server and handles graceful shutdown
-func (s *Server) Run() {
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
-
-	go func() {
-		s.logger.Info("server starting", "port", s.cfg.Port)
-		if err := s.http.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			s.logger.Error("error starting server", "error", err)
-		}
-	}()
+// Run starts the HTTP servers and shuts them down gracefully once ctx is done
+func (s *Server) Run(ctx context.Context) {
+	go s.listen("server starting", s.http)
+	if s.admin != nil {
+		go s.listen("admin server starting", s.admin)
+	}
 
-	<-stop
+	<-ctx.Done()
 	s.logger.Info("shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := s.http.Shutdown(ctx); err != nil {
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
 		s.logger.Error("error during server shutdown", "error", err)
 	}
+	if s.admin != nil {
+		if err := s.admin.Shutdown(shutdownCtx); err != nil {
+			s.logger.Error("error during admin server shutdown", "error", err)
+		}
+	}
 
 	s.logger.Info("server stopped gracefully")
 }
+
+func (s *Server) listen(msg string, srv *http.Server) {
+	s.logger.Info(msg, "port", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		s.logger.Error("error starting server", "port", srv.Addr, "error", err)
+	}
+}
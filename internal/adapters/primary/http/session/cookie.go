@@ -0,0 +1,300 @@
+// Package session keeps state of browser clients between requests.
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// chunkSize keeps each cookie below the 4096 byte limit of browsers, including
+	// its name and attributes
+	chunkSize = 3800
+	maxChunks = 8
+)
+
+// ErrNotFound is returned when a request carries no valid session: the cookie is
+// missing, expired, or was not sealed with this secret
+var ErrNotFound = errors.New("session: not found")
+
+// Store keeps the value of a browser session
+type Store interface {
+	// Load decodes the session of r into v, or returns ErrNotFound
+	Load(r *http.Request, v any) error
+	// Save stores v as the session of r
+	Save(w http.ResponseWriter, r *http.Request, v any) error
+	// Renew stores v under a new session ID; call it when privileges change, e.g. on
+	// sign-in, so an ID planted by an attacker never becomes authenticated
+	Renew(w http.ResponseWriter, r *http.Request, v any) error
+	// Clear ends the session of r
+	Clear(w http.ResponseWriter, r *http.Request) error
+	// ID returns an identifier of the session of r that stays the same until the
+	// session is renewed, or ErrNotFound; it is secret like the session itself
+	ID(r *http.Request) (string, error)
+}
+
+// CookieOptions are the attributes of the cookies set by this package; cookies are
+// always HttpOnly and scoped to the whole site
+type CookieOptions struct {
+	Secure   bool
+	SameSite http.SameSite
+	// Domain shares the cookie with subdomains; empty keeps it on the exact host
+	Domain string
+}
+
+// ParseSameSite maps SESSION_COOKIE_SAMESITE to its attribute; anything but strict is lax
+func ParseSameSite(mode string) http.SameSite {
+	if mode == "strict" {
+		return http.SameSiteStrictMode
+	}
+	return http.SameSiteLaxMode
+}
+
+func (o CookieOptions) cookie(name, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   o.Domain,
+		MaxAge:   maxAge,
+		Secure:   o.Secure,
+		HttpOnly: true,
+		SameSite: o.SameSite,
+	}
+}
+
+// Cookies seals values into encrypted and authenticated cookies with AES-256-GCM.
+// Values too large for one cookie are split over several named <name>, <name>_1, ...
+type Cookies struct {
+	aead   cipher.AEAD
+	macKey []byte
+	opts   CookieOptions
+}
+
+// NewCookies creates Cookies keyed by secret. Use SameSite lax for state that must
+// survive the redirect back from another site, such as a login in progress.
+func NewCookies(secret string, opts CookieOptions) (*Cookies, error) {
+	if len(secret) < 32 {
+		return nil, errors.New("session cookie secret must be at least 32 bytes")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	mac := sha256.Sum256([]byte("mac:" + secret))
+	return &Cookies{aead: aead, macKey: mac[:], opts: opts}, nil
+}
+
+type sealed struct {
+	Expires int64           `json:"exp"`
+	Value   json.RawMessage `json:"v"`
+}
+
+// Read decodes the cookie name of r into v, or returns ErrNotFound
+func (c *Cookies) Read(r *http.Request, name string, v any) error {
+	first, err := r.Cookie(name)
+	if err != nil {
+		return ErrNotFound
+	}
+	var encoded strings.Builder
+	encoded.WriteString(first.Value)
+	for i := 1; i < maxChunks; i++ {
+		chunk, err := r.Cookie(chunkName(name, i))
+		if err != nil {
+			break
+		}
+		encoded.WriteString(chunk.Value)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded.String())
+	if err != nil || len(data) < c.aead.NonceSize() {
+		return ErrNotFound
+	}
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	// The name is authenticated so a value can't be moved to another cookie
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return ErrNotFound
+	}
+
+	var s sealed
+	if err := json.Unmarshal(plaintext, &s); err != nil || time.Now().Unix() >= s.Expires {
+		return ErrNotFound
+	}
+	if err := json.Unmarshal(s.Value, v); err != nil {
+		return fmt.Errorf("failed to decode session cookie %s: %w", name, err)
+	}
+	return nil
+}
+
+// Write seals v into the cookie name for ttl, removing chunks left over from a larger
+// previous value
+func (c *Cookies) Write(w http.ResponseWriter, r *http.Request, name string, v any, ttl time.Duration) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode session cookie %s: %w", name, err)
+	}
+	plaintext, err := json.Marshal(sealed{Expires: time.Now().Add(ttl).Unix(), Value: value})
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, plaintext, []byte(name)))
+
+	chunks := (len(encoded) + chunkSize - 1) / chunkSize
+	if chunks > maxChunks {
+		return fmt.Errorf("session cookie %s is too large: %d bytes", name, len(encoded))
+	}
+	for i := range chunks {
+		end := min((i+1)*chunkSize, len(encoded))
+		http.SetCookie(w, c.opts.cookie(chunkName(name, i), encoded[i*chunkSize:end], int(ttl.Seconds())))
+	}
+	c.deleteChunks(w, r, name, chunks)
+	return nil
+}
+
+// Delete expires the cookie name and all of its chunks
+func (c *Cookies) Delete(w http.ResponseWriter, r *http.Request, name string) {
+	c.deleteChunks(w, r, name, 0)
+}
+
+func (c *Cookies) deleteChunks(w http.ResponseWriter, r *http.Request, name string, from int) {
+	for i := from; i < maxChunks; i++ {
+		if _, err := r.Cookie(chunkName(name, i)); err == nil {
+			http.SetCookie(w, c.opts.cookie(chunkName(name, i), "", -1))
+		}
+	}
+}
+
+// CSRFToken returns the CSRF token of the session sessionID, see Store.ID. Tokens are
+// bound to the session, so a token obtained in another session, e.g. by an attacker
+// who then plants cookies through a sibling subdomain, never matches; they change
+// whenever the session is renewed.
+func (c *Cookies) CSRFToken(sessionID string) string {
+	h := hmac.New(sha256.New, c.macKey)
+	h.Write([]byte("csrf:" + sessionID))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// VerifyCSRF reports whether token is the CSRF token of the session sessionID
+func (c *Cookies) VerifyCSRF(sessionID, token string) bool {
+	if sessionID == "" || token == "" {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(c.CSRFToken(sessionID)))
+}
+
+func chunkName(name string, i int) string {
+	if i == 0 {
+		return name
+	}
+	return name + "_" + strconv.Itoa(i)
+}
+
+// CookieStore keeps the whole session in sealed cookies, so no server-side storage
+// is needed. It has no idle timeout, and ending a session only deletes the cookie in
+// the browser it was sent to.
+type CookieStore struct {
+	cookies *Cookies
+	name    string
+	ttl     time.Duration
+}
+
+// cookieSession is the sealed value of a CookieStore session
+type cookieSession struct {
+	ID    string          `json:"id"`
+	Value json.RawMessage `json:"v"`
+}
+
+// NewCookieStore creates a Store in the cookie name that expires after ttl
+func NewCookieStore(cookies *Cookies, name string, ttl time.Duration) *CookieStore {
+	return &CookieStore{cookies: cookies, name: name, ttl: ttl}
+}
+
+// Load decodes the session of r into v
+func (s *CookieStore) Load(r *http.Request, v any) error {
+	var sess cookieSession
+	if err := s.cookies.Read(r, s.name, &sess); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(sess.Value, v); err != nil {
+		return fmt.Errorf("failed to decode session: %w", err)
+	}
+	return nil
+}
+
+// Save seals v into the session cookie, keeping the ID of the current session
+func (s *CookieStore) Save(w http.ResponseWriter, r *http.Request, v any) error {
+	id, err := s.ID(r)
+	if errors.Is(err, ErrNotFound) {
+		return s.Renew(w, r, v)
+	}
+	if err != nil {
+		return err
+	}
+	return s.write(w, r, id, v)
+}
+
+// Renew seals v into the session cookie under a new ID
+func (s *CookieStore) Renew(w http.ResponseWriter, r *http.Request, v any) error {
+	id, err := randomID()
+	if err != nil {
+		return err
+	}
+	return s.write(w, r, id, v)
+}
+
+// Clear deletes the session cookie
+func (s *CookieStore) Clear(w http.ResponseWriter, r *http.Request) error {
+	s.cookies.Delete(w, r, s.name)
+	return nil
+}
+
+// ID returns the ID sealed into the session cookie
+func (s *CookieStore) ID(r *http.Request) (string, error) {
+	var sess cookieSession
+	if err := s.cookies.Read(r, s.name, &sess); err != nil {
+		return "", err
+	}
+	if sess.ID == "" {
+		return "", ErrNotFound
+	}
+	return sess.ID, nil
+}
+
+func (s *CookieStore) write(w http.ResponseWriter, r *http.Request, id string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	return s.cookies.Write(w, r, s.name, cookieSession{ID: id, Value: value}, s.ttl)
+}
+
+// randomID returns 32 random bytes, base64url encoded
+func randomID() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,201 @@
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/core/domain"
+	"go-chi-boilerplate/internal/core/ports"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ManagerOptions configure a Manager
+type ManagerOptions struct {
+	CookieName string
+	Cookie     CookieOptions
+	// AbsoluteTimeout ends a session this long after it started, however active it is
+	AbsoluteTimeout time.Duration
+	// IdleTimeout ends a session that was not used for this long; zero disables it
+	IdleTimeout time.Duration
+}
+
+// Manager keeps sessions server-side in a ports.SessionStore; the browser only holds
+// a random session ID and the store only its SHA-256, so a leaked store can't be used
+// to hijack sessions. It implements Store.
+type Manager struct {
+	store  ports.SessionStore
+	opts   ManagerOptions
+	logger *slog.Logger
+}
+
+// NewManager creates a Manager on store
+func NewManager(store ports.SessionStore, opts ManagerOptions, logger *slog.Logger) *Manager {
+	return &Manager{store: store, opts: opts, logger: logger}
+}
+
+// Load decodes the session of r into v and records the activity for the idle timeout
+func (m *Manager) Load(r *http.Request, v any) error {
+	sess, err := m.current(r)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(sess.Data, v); err != nil {
+		return fmt.Errorf("failed to decode session: %w", err)
+	}
+
+	m.touch(r.Context(), sess)
+	return nil
+}
+
+// Save stores v in the session of r, starting a session when there is none
+func (m *Manager) Save(w http.ResponseWriter, r *http.Request, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	sess, err := m.current(r)
+	if errors.Is(err, ErrNotFound) {
+		return m.start(w, r, data, time.Now())
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	sess.Data = data
+	sess.LastActiveAt = now
+	sess.ExpiresAt = m.expiry(sess.CreatedAt, now)
+	return m.store.Save(r.Context(), sess)
+}
+
+// Renew moves the session of r to a new ID and stores v in it. The absolute timeout
+// still counts from the start of the original session.
+func (m *Manager) Renew(w http.ResponseWriter, r *http.Request, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	createdAt := time.Now()
+	old, err := m.current(r)
+	switch {
+	case err == nil:
+		createdAt = old.CreatedAt
+		if err := m.store.Delete(r.Context(), old.ID); err != nil {
+			return err
+		}
+	case !errors.Is(err, ErrNotFound):
+		return err
+	}
+	return m.start(w, r, data, createdAt)
+}
+
+// Clear deletes the session of r and its cookie
+func (m *Manager) Clear(w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, m.opts.Cookie.cookie(m.opts.CookieName, "", -1))
+
+	sess, err := m.current(r)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return m.store.Delete(r.Context(), sess.ID)
+}
+
+// ID returns the storage key of the session of r, which changes on Renew
+func (m *Manager) ID(r *http.Request) (string, error) {
+	sess, err := m.current(r)
+	if err != nil {
+		return "", err
+	}
+	return sess.ID, nil
+}
+
+// current returns the stored session of r, or ErrNotFound when it has none or it
+// timed out
+func (m *Manager) current(r *http.Request) (*domain.Session, error) {
+	cookie, err := r.Cookie(m.opts.CookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, ErrNotFound
+	}
+
+	sess, err := m.store.Get(r.Context(), storageKey(cookie.Value))
+	if errors.Is(err, ports.ErrSessionNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Stores may keep expired sessions for a while
+	if now := time.Now(); !now.Before(sess.ExpiresAt) || !now.Before(sess.CreatedAt.Add(m.opts.AbsoluteTimeout)) {
+		return nil, ErrNotFound
+	}
+	return sess, nil
+}
+
+// start stores a session under a new ID and sends its cookie
+func (m *Manager) start(w http.ResponseWriter, r *http.Request, data []byte, createdAt time.Time) error {
+	id, err := randomID()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	sess := &domain.Session{
+		ID:           storageKey(id),
+		Data:         data,
+		CreatedAt:    createdAt,
+		LastActiveAt: now,
+		ExpiresAt:    m.expiry(createdAt, now),
+	}
+	if err := m.store.Save(r.Context(), sess); err != nil {
+		return err
+	}
+
+	maxAge := int(time.Until(createdAt.Add(m.opts.AbsoluteTimeout)).Seconds())
+	http.SetCookie(w, m.opts.Cookie.cookie(m.opts.CookieName, id, maxAge))
+	return nil
+}
+
+// touch extends the idle timeout; the store is written at most every tenth of the
+// idle timeout, and no more than once a minute
+func (m *Manager) touch(ctx context.Context, sess *domain.Session) {
+	if m.opts.IdleTimeout <= 0 {
+		return
+	}
+	now := time.Now()
+	if now.Sub(sess.LastActiveAt) < max(m.opts.IdleTimeout/10, time.Minute) {
+		return
+	}
+
+	sess.LastActiveAt = now
+	sess.ExpiresAt = m.expiry(sess.CreatedAt, now)
+	if err := m.store.Save(ctx, sess); err != nil {
+		m.logger.WarnContext(ctx, "failed to record session activity", "error", err)
+	}
+}
+
+// expiry is when a session started at createdAt and last used at lastActive ends
+func (m *Manager) expiry(createdAt, lastActive time.Time) time.Time {
+	expiresAt := createdAt.Add(m.opts.AbsoluteTimeout)
+	if m.opts.IdleTimeout > 0 {
+		if idle := lastActive.Add(m.opts.IdleTimeout); idle.Before(expiresAt) {
+			return idle
+		}
+	}
+	return expiresAt
+}
+
+func storageKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
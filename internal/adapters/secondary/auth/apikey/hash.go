@@ -0,0 +1,89 @@
+package apikey
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2id parameters, the OWASP minimum for argon2id
+const (
+	argonTime    = 2
+	argonMemory  = 19 * 1024
+	argonThreads = 1
+	argonKeyLen  = 32
+)
+
+// hashSecret hashes secret with HMAC-SHA256 keyed by the pepper and, for argon2id,
+// stretches the result with a random salt. The result names its algorithm, so keys
+// hashed before API_KEY_HASH changed still verify.
+func hashSecret(algorithm string, pepper []byte, secret string) (string, error) {
+	mac := peppered(pepper, secret)
+
+	switch algorithm {
+	case "sha256":
+		return "sha256$" + base64.RawStdEncoding.EncodeToString(mac), nil
+	case "argon2id":
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		sum := argon2.IDKey(mac, salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argonMemory, argonTime, argonThreads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(sum)), nil
+	default:
+		return "", fmt.Errorf("unsupported api key hash %q", algorithm)
+	}
+}
+
+// verifySecret reports whether secret matches a hash produced by hashSecret
+func verifySecret(hash string, pepper []byte, secret string) (bool, error) {
+	mac := peppered(pepper, secret)
+
+	if encoded, ok := strings.CutPrefix(hash, "sha256$"); ok {
+		want, err := base64.RawStdEncoding.DecodeString(encoded)
+		if err != nil {
+			return false, fmt.Errorf("malformed sha256 api key hash: %w", err)
+		}
+		return hmac.Equal(mac, want), nil
+	}
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, errors.New("unrecognized api key hash format")
+	}
+
+	var version int
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false, fmt.Errorf("malformed argon2 parameters %q: %w", parts[3], err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("malformed argon2 salt: %w", err)
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, fmt.Errorf("malformed argon2 hash: %w", err)
+	}
+
+	sum := argon2.IDKey(mac, salt, time, memory, threads, uint32(len(want)))
+	return subtle.ConstantTimeCompare(sum, want) == 1, nil
+}
+
+func peppered(pepper []byte, secret string) []byte {
+	h := hmac.New(sha256.New, pepper)
+	h.Write([]byte(secret))
+	return h.Sum(nil)
+}
@@ -0,0 +1,272 @@
+// Package apikey issues and verifies API keys for machine clients. A key has the form
+// <API_KEY_PREFIX>_<lookup>_<secret>: the lookup part finds the stored key and only a
+// peppered hash of the secret is stored.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/core/auth"
+	"go-chi-boilerplate/internal/core/domain"
+	"go-chi-boilerplate/internal/core/ports"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	lookupBytes = 8
+	secretBytes = 32
+	// maxCached bounds the verification cache; it is cleared when full
+	maxCached = 10000
+)
+
+// Manager issues, lists, revokes and verifies API keys. It implements
+// ports.TokenVerifier so verified keys become an auth.Principal with Method "api_key".
+type Manager struct {
+	cfg    *config.APIKeyConfigs
+	repo   ports.APIKeyRepository
+	pepper []byte
+	logger *slog.Logger
+
+	mu sync.Mutex
+	// verified caches keys by the SHA-256 of the presented key for CacheTTL, which
+	// spares the hash and the lookup on every request
+	verified map[[sha256.Size]byte]cachedKey
+	// touched is when the last use of each key was last written
+	touched map[string]time.Time
+}
+
+type cachedKey struct {
+	key      *domain.APIKey
+	cachedAt time.Time
+}
+
+// New creates a Manager storing keys in repo; it needs API_KEY_PEPPER even when
+// API_KEYS_ENABLED is off so the CLI can issue keys ahead of enabling them
+func New(cfg *config.APIKeyConfigs, repo ports.APIKeyRepository, logger *slog.Logger) (*Manager, error) {
+	if cfg.Pepper == "" {
+		return nil, errors.New("API_KEY_PEPPER is required to issue or verify api keys")
+	}
+	return &Manager{
+		cfg:      cfg,
+		repo:     repo,
+		pepper:   []byte(cfg.Pepper),
+		logger:   logger,
+		verified: make(map[[sha256.Size]byte]cachedKey),
+		touched:  make(map[string]time.Time),
+	}, nil
+}
+
+// Issue creates a key with the given scopes that expires after ttl, or never when ttl
+// is zero. The returned plaintext key is not stored and can't be shown again.
+func (m *Manager) Issue(ctx context.Context, name string, scopes []string, ttl time.Duration) (string, *domain.APIKey, error) {
+	lookup, err := randomBytes(lookupBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	secretRaw, err := randomBytes(secretBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	prefix := hex.EncodeToString(lookup)
+	secret := base64.RawURLEncoding.EncodeToString(secretRaw)
+
+	hash, err := hashSecret(m.cfg.Hash, m.pepper, secret)
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := &domain.APIKey{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hash,
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+	if ttl > 0 {
+		expiresAt := key.CreatedAt.Add(ttl)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := m.repo.Create(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s_%s_%s", m.cfg.Prefix, prefix, secret), key, nil
+}
+
+// List returns every key, newest first
+func (m *Manager) List(ctx context.Context) ([]domain.APIKey, error) {
+	return m.repo.List(ctx)
+}
+
+// Revoke revokes the key with the given ID. Other instances keep accepting the key
+// for up to API_KEY_CACHE_TTL.
+func (m *Manager) Revoke(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperrors.NotFound("api_key_not_found", "the API key does not exist")
+	}
+	if err := m.repo.Revoke(ctx, id); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	for sum, cached := range m.verified {
+		if cached.key.ID == id {
+			delete(m.verified, sum)
+		}
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// Verify checks the key and returns its principal; unknown, revoked and expired keys
+// return an apperrors.Unauthorized error
+func (m *Manager) Verify(ctx context.Context, token string) (*auth.Principal, error) {
+	now := time.Now()
+	sum := sha256.Sum256([]byte(token))
+
+	key, err := m.cached(sum, now)
+	if key == nil && err == nil {
+		key, err = m.lookup(ctx, token)
+		if err == nil {
+			m.cache(sum, key, now)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !key.Active(now) {
+		return nil, invalidKey(fmt.Errorf("api key %s is revoked or expired", key.ID))
+	}
+
+	m.touch(ctx, key, now)
+	return principal(key), nil
+}
+
+func (m *Manager) lookup(ctx context.Context, token string) (*domain.APIKey, error) {
+	rest, ok := strings.CutPrefix(token, m.cfg.Prefix+"_")
+	if !ok {
+		return nil, invalidKey(errors.New("api key has the wrong prefix"))
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 2*lookupBytes || secret == "" {
+		return nil, invalidKey(errors.New("malformed api key"))
+	}
+	if _, err := hex.DecodeString(prefix); err != nil {
+		return nil, invalidKey(errors.New("malformed api key"))
+	}
+
+	key, err := m.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if apperrors.From(err).Kind == apperrors.KindNotFound {
+			return nil, invalidKey(err)
+		}
+		return nil, err
+	}
+
+	match, err := verifySecret(key.Hash, m.pepper, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify api key %s: %w", key.ID, err)
+	}
+	if !match {
+		return nil, invalidKey(fmt.Errorf("secret of api key %s does not match", key.ID))
+	}
+	return key, nil
+}
+
+func (m *Manager) cached(sum [sha256.Size]byte, now time.Time) (*domain.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cached, ok := m.verified[sum]
+	if !ok {
+		return nil, nil
+	}
+	if now.Sub(cached.cachedAt) >= m.cfg.CacheTTL {
+		delete(m.verified, sum)
+		return nil, nil
+	}
+	return cached.key, nil
+}
+
+func (m *Manager) cache(sum [sha256.Size]byte, key *domain.APIKey, now time.Time) {
+	if m.cfg.CacheTTL <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.verified) >= maxCached {
+		clear(m.verified)
+	}
+	m.verified[sum] = cachedKey{key: key, cachedAt: now}
+}
+
+// touch records the last use of key in the background, at most once per
+// API_KEY_LAST_USED_INTERVAL
+func (m *Manager) touch(ctx context.Context, key *domain.APIKey, now time.Time) {
+	m.mu.Lock()
+	last, ok := m.touched[key.ID]
+	if !ok && key.LastUsedAt != nil {
+		last = *key.LastUsedAt
+	}
+	if now.Sub(last) < m.cfg.LastUsedInterval {
+		m.mu.Unlock()
+		return
+	}
+	m.touched[key.ID] = now
+	m.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := m.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			m.logger.WarnContext(ctx, "failed to record api key use", "key_id", key.ID, "error", err)
+		}
+	}()
+}
+
+func principal(key *domain.APIKey) *auth.Principal {
+	p := &auth.Principal{
+		Subject: key.ID,
+		Scopes:  key.Scopes,
+		Method:  "api_key",
+		Claims: map[string]any{
+			"key_id": key.ID,
+			"name":   key.Name,
+			"prefix": key.Prefix,
+		},
+	}
+	if key.ExpiresAt != nil {
+		p.ExpiresAt = *key.ExpiresAt
+	}
+	return p
+}
+
+func invalidKey(err error) error {
+	return apperrors.Unauthorized("invalid_api_key", "the API key is invalid").Wrap(err)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return b, nil
+}
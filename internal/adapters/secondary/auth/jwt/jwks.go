@@ -0,0 +1,252 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotFound is returned when the key set has no key with the requested ID
+var ErrKeyNotFound = errors.New("jwks: key not found")
+
+// JWKS fetches a JSON Web Key Set and caches its keys by key ID. Keys are refetched
+// once the set is older than refresh, and earlier when a token names an unknown key
+// ID so rotated keys are picked up, but at most once per minRefresh. When a refetch
+// fails the previous keys stay in use.
+type JWKS struct {
+	url        string
+	refresh    time.Duration
+	minRefresh time.Duration
+	client     *http.Client
+	logger     *slog.Logger
+
+	mu          sync.RWMutex
+	keys        map[string]any
+	fetchedAt   time.Time
+	lastAttempt time.Time
+
+	// fetchMu serializes fetches so concurrent misses trigger a single request
+	fetchMu sync.Mutex
+}
+
+// NewJWKS creates a key set cache for url; nothing is fetched until the first lookup
+func NewJWKS(url string, refresh, minRefresh time.Duration, logger *slog.Logger) *JWKS {
+	return &JWKS{
+		url:        url,
+		refresh:    refresh,
+		minRefresh: minRefresh,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		logger:     logger,
+	}
+}
+
+// Key returns the public key with the given ID; an empty kid matches the only key of
+// a single-key set
+func (j *JWKS) Key(ctx context.Context, kid string) (any, error) {
+	j.mu.RLock()
+	key, found := j.lookup(kid)
+	stale := time.Since(j.fetchedAt) > j.refresh
+	canRetry := time.Since(j.lastAttempt) > j.minRefresh
+	j.mu.RUnlock()
+
+	if found && !stale {
+		return key, nil
+	}
+	if !stale && !canRetry {
+		return nil, ErrKeyNotFound
+	}
+
+	if err := j.fetch(ctx); err != nil {
+		if found {
+			j.logger.WarnContext(ctx, "failed to refresh jwks, using cached keys", "url", j.url, "error", err)
+			return key, nil
+		}
+		return nil, err
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if key, found := j.lookup(kid); found {
+		return key, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+// lookup must be called with mu held
+func (j *JWKS) lookup(kid string) (any, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+// fetch downloads the key set unless it was attempted within minRefresh, e.g. by a
+// concurrent caller this one waited for or while the endpoint is failing
+func (j *JWKS) fetch(ctx context.Context) error {
+	j.fetchMu.Lock()
+	defer j.fetchMu.Unlock()
+
+	j.mu.RLock()
+	recent := !j.lastAttempt.IsZero() && time.Since(j.lastAttempt) <= j.minRefresh
+	j.mu.RUnlock()
+	if recent {
+		return nil
+	}
+
+	j.mu.Lock()
+	j.lastAttempt = time.Now()
+	j.mu.Unlock()
+
+	keys, err := j.download(ctx)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+
+	j.logger.InfoContext(ctx, "jwks refreshed", "url", j.url, "keys", len(keys))
+	return nil
+}
+
+func (j *JWKS) download(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks: %s responded %s", j.url, resp.Status)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		// Encryption keys can't verify signatures
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			j.logger.WarnContext(ctx, "skipping unusable jwk", "kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+// jsonWebKey holds the RFC 7517 members of RSA, EC and OKP public keys
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
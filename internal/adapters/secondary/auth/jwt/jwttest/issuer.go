@@ -0,0 +1,180 @@
+// Package jwttest runs a local token issuer for tests: it serves a JWKS over HTTP and
+// signs tokens with RS256, ES256, EdDSA or HS256.
+package jwttest
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// Secret is the HS256 key; pass it as AUTH_HMAC_SECRET
+const Secret = "jwttest-hmac-secret-0123456789abcdef"
+
+// Issuer signs tokens and serves the matching public keys at JWKSURL
+type Issuer struct {
+	// URL is the issuer's base URL and the default iss claim
+	URL string
+	// JWKSURL serves the public keys; pass it as AUTH_JWKS_URL
+	JWKSURL string
+
+	server *httptest.Server
+
+	mu         sync.RWMutex
+	generation int
+	fetches    int
+	rsaKey     *rsa.PrivateKey
+	ecKey      *ecdsa.PrivateKey
+	edKey      ed25519.PrivateKey
+}
+
+// NewIssuer starts an Issuer that is closed when the test ends
+func NewIssuer(tb testing.TB) *Issuer {
+	tb.Helper()
+
+	i := &Issuer{}
+	if err := i.generate(); err != nil {
+		tb.Fatalf("jwttest: failed to generate keys: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/jwks.json", i.serveJWKS)
+	i.server = httptest.NewServer(mux)
+	i.URL = i.server.URL
+	i.JWKSURL = i.server.URL + "/.well-known/jwks.json"
+	tb.Cleanup(i.server.Close)
+
+	return i
+}
+
+// Rotate replaces every asymmetric key with a new one under a new key ID; tokens signed
+// before stay verifiable only while a verifier still has the old keys cached
+func (i *Issuer) Rotate(tb testing.TB) {
+	tb.Helper()
+	if err := i.generate(); err != nil {
+		tb.Fatalf("jwttest: failed to rotate keys: %v", err)
+	}
+}
+
+// Claims returns standard claims for subject: iss set to URL, aud, iat now and exp
+// after ttl
+func (i *Issuer) Claims(subject, audience string, ttl time.Duration) gojwt.MapClaims {
+	now := time.Now()
+	return gojwt.MapClaims{
+		"iss": i.URL,
+		"sub": subject,
+		"aud": audience,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+}
+
+// Sign signs claims with alg, one of RS256, ES256, EdDSA or HS256
+func (i *Issuer) Sign(tb testing.TB, alg string, claims gojwt.MapClaims) string {
+	tb.Helper()
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var method gojwt.SigningMethod
+	var key any
+	switch alg {
+	case "RS256":
+		method, key = gojwt.SigningMethodRS256, i.rsaKey
+	case "ES256":
+		method, key = gojwt.SigningMethodES256, i.ecKey
+	case "EdDSA":
+		method, key = gojwt.SigningMethodEdDSA, i.edKey
+	case "HS256":
+		method, key = gojwt.SigningMethodHS256, []byte(Secret)
+	default:
+		tb.Fatalf("jwttest: unsupported algorithm %q", alg)
+	}
+
+	token := gojwt.NewWithClaims(method, claims)
+	if alg != "HS256" {
+		token.Header["kid"] = i.kid(alg)
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		tb.Fatalf("jwttest: failed to sign token: %v", err)
+	}
+	return signed
+}
+
+// Fetches returns how often the JWKS was downloaded
+func (i *Issuer) Fetches() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.fetches
+}
+
+func (i *Issuer) generate() error {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.generation++
+	i.rsaKey, i.ecKey, i.edKey = rsaKey, ecKey, edKey
+	return nil
+}
+
+// kid must be called with mu held
+func (i *Issuer) kid(alg string) string {
+	return fmt.Sprintf("%s-%d", alg, i.generation)
+}
+
+func (i *Issuer) serveJWKS(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	i.fetches++
+	i.mu.Unlock()
+
+	i.mu.RLock()
+	keys := []map[string]string{
+		{
+			"kty": "RSA", "kid": i.kid("RS256"), "use": "sig", "alg": "RS256",
+			"n": b64(i.rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(i.rsaKey.E)).Bytes()),
+		},
+		{
+			"kty": "EC", "kid": i.kid("ES256"), "use": "sig", "alg": "ES256", "crv": "P-256",
+			"x": b64(i.ecKey.X.FillBytes(make([]byte, 32))), "y": b64(i.ecKey.Y.FillBytes(make([]byte, 32))),
+		},
+		{
+			"kty": "OKP", "kid": i.kid("EdDSA"), "use": "sig", "alg": "EdDSA", "crv": "Ed25519",
+			"x": b64(i.edKey.Public().(ed25519.PublicKey)),
+		},
+	}
+	i.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,103 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/core/auth"
+	"log/slog"
+	"slices"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// Verifier validates bearer JWTs signed with a key from a JWKS (RS256, ES256, EdDSA)
+// or with a shared secret (HS256). It implements ports.TokenVerifier.
+type Verifier struct {
+	cfg    *config.AuthConfigs
+	jwks   *JWKS
+	secret []byte
+	parser *gojwt.Parser
+}
+
+// New creates a Verifier. Algorithms are limited to those AUTH_ALGORITHMS lists and
+// a key source is configured for: HS256 needs AUTH_HMAC_SECRET, the others AUTH_JWKS_URL.
+func New(cfg *config.AuthConfigs, logger *slog.Logger) (*Verifier, error) {
+	v := &Verifier{cfg: cfg}
+	if cfg.JWKSURL != "" {
+		v.jwks = NewJWKS(cfg.JWKSURL, cfg.JWKSRefresh, cfg.JWKSMinRefresh, logger)
+	}
+	if cfg.HMACSecret != "" {
+		v.secret = []byte(cfg.HMACSecret)
+	}
+
+	var algs []string
+	for _, alg := range cfg.Algorithms {
+		if (alg == "HS256" && v.secret != nil) || (alg != "HS256" && v.jwks != nil) {
+			algs = append(algs, alg)
+		}
+	}
+	if len(algs) == 0 {
+		return nil, errors.New("no JWT algorithm has a key source: set AUTH_JWKS_URL or AUTH_HMAC_SECRET to match AUTH_ALGORITHMS")
+	}
+
+	opts := []gojwt.ParserOption{
+		gojwt.WithValidMethods(algs),
+		gojwt.WithLeeway(cfg.ClockSkew),
+		gojwt.WithExpirationRequired(),
+		gojwt.WithIssuedAt(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, gojwt.WithIssuer(cfg.Issuer))
+	}
+	v.parser = gojwt.NewParser(opts...)
+
+	return v, nil
+}
+
+// Verify checks the signature, expiry, not-before, issuer and audience of token and
+// returns its principal
+func (v *Verifier) Verify(ctx context.Context, token string) (*auth.Principal, error) {
+	claims := gojwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *gojwt.Token) (any, error) {
+		if t.Method.Alg() == "HS256" {
+			return v.secret, nil
+		}
+		kid, _ := t.Header["kid"].(string)
+		return v.jwks.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, apperrors.Unauthorized("invalid_token", "the access token is invalid").Wrap(err)
+	}
+
+	audience, _ := claims.GetAudience()
+	if len(v.cfg.Audience) > 0 && !slices.ContainsFunc(audience, func(aud string) bool { return slices.Contains(v.cfg.Audience, aud) }) {
+		return nil, apperrors.Unauthorized("invalid_token", "the access token is invalid").
+			Wrap(fmt.Errorf("audience %v does not include any of %v", audience, v.cfg.Audience))
+	}
+
+	return v.principal(claims, audience), nil
+}
+
+func (v *Verifier) principal(claims gojwt.MapClaims, audience []string) *auth.Principal {
+	p := &auth.Principal{
+		Audience: audience,
+		Scopes:   auth.StringList(auth.ClaimAt(claims, v.cfg.ScopesClaim)),
+		Roles:    auth.StringList(auth.ClaimAt(claims, v.cfg.RolesClaim)),
+		Method:   "jwt",
+		Claims:   claims,
+	}
+	p.Subject, _ = claims.GetSubject()
+	p.Issuer, _ = claims.GetIssuer()
+	if exp, _ := claims.GetExpirationTime(); exp != nil {
+		p.ExpiresAt = exp.Time
+	}
+
+	// Azure AD and others use scp instead of scope
+	if len(p.Scopes) == 0 && v.cfg.ScopesClaim == "scope" {
+		p.Scopes = auth.StringList(claims["scp"])
+	}
+	return p
+}
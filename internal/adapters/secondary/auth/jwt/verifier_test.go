@@ -0,0 +1,248 @@
+package jwt_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/jwt"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/jwt/jwttest"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+const audience = "api"
+
+func newVerifier(t *testing.T, iss *jwttest.Issuer, configure func(cfg *config.AuthConfigs)) *jwt.Verifier {
+	t.Helper()
+
+	cfg := &config.AuthConfigs{
+		JWKSURL:        iss.JWKSURL,
+		HMACSecret:     jwttest.Secret,
+		Issuer:         iss.URL,
+		Audience:       []string{audience},
+		Algorithms:     []string{"RS256", "ES256", "EdDSA", "HS256"},
+		ClockSkew:      30 * time.Second,
+		JWKSRefresh:    time.Hour,
+		JWKSMinRefresh: time.Hour,
+		ScopesClaim:    "scope",
+		RolesClaim:     "roles",
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+
+	v, err := jwt.New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return v
+}
+
+func assertRejected(t *testing.T, v *jwt.Verifier, token string) {
+	t.Helper()
+
+	_, err := v.Verify(context.Background(), token)
+	if err == nil {
+		t.Fatal("token was accepted")
+	}
+	if kind := apperrors.From(err).Kind; kind != apperrors.KindUnauthorized {
+		t.Fatalf("error kind = %v, want unauthorized (%v)", kind, err)
+	}
+}
+
+func TestVerifyAcceptsEveryAlgorithm(t *testing.T) {
+	iss := jwttest.NewIssuer(t)
+	v := newVerifier(t, iss, nil)
+
+	for _, alg := range []string{"RS256", "ES256", "EdDSA", "HS256"} {
+		t.Run(alg, func(t *testing.T) {
+			claims := iss.Claims("user-1", audience, time.Minute)
+			claims["scope"] = "orders:read orders:write"
+
+			p, err := v.Verify(context.Background(), iss.Sign(t, alg, claims))
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if p.Subject != "user-1" || p.Method != "jwt" || !p.HasScope("orders:write") {
+				t.Fatalf("unexpected principal %+v", p)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsAlgorithmConfusion(t *testing.T) {
+	iss := jwttest.NewIssuer(t)
+
+	t.Run("HS256 without a shared secret", func(t *testing.T) {
+		v := newVerifier(t, iss, func(cfg *config.AuthConfigs) { cfg.HMACSecret = "" })
+		assertRejected(t, v, iss.Sign(t, "HS256", iss.Claims("user-1", audience, time.Minute)))
+	})
+
+	t.Run("HS256 keyed with the JWKS public key", func(t *testing.T) {
+		v := newVerifier(t, iss, nil)
+
+		// The classic attack signs with the public key as HMAC secret and names its kid
+		publicKey := rsaModulus(t, iss)
+		token := gojwt.NewWithClaims(gojwt.SigningMethodHS256, iss.Claims("user-1", audience, time.Minute))
+		token.Header["kid"] = "RS256-1"
+		signed, err := token.SignedString(publicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertRejected(t, v, signed)
+	})
+
+	t.Run("alg none", func(t *testing.T) {
+		v := newVerifier(t, iss, nil)
+
+		token := gojwt.NewWithClaims(gojwt.SigningMethodNone, iss.Claims("user-1", audience, time.Minute))
+		signed, err := token.SignedString(gojwt.UnsafeAllowNoneSignatureType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertRejected(t, v, signed)
+	})
+
+	t.Run("algorithm not allowed", func(t *testing.T) {
+		v := newVerifier(t, iss, func(cfg *config.AuthConfigs) { cfg.Algorithms = []string{"ES256"} })
+		assertRejected(t, v, iss.Sign(t, "RS256", iss.Claims("user-1", audience, time.Minute)))
+	})
+}
+
+func TestVerifyExpiry(t *testing.T) {
+	iss := jwttest.NewIssuer(t)
+	v := newVerifier(t, iss, nil)
+
+	t.Run("expired", func(t *testing.T) {
+		assertRejected(t, v, iss.Sign(t, "RS256", iss.Claims("user-1", audience, -time.Minute)))
+	})
+
+	t.Run("expired within the clock skew", func(t *testing.T) {
+		token := iss.Sign(t, "RS256", iss.Claims("user-1", audience, -10*time.Second))
+		if _, err := v.Verify(context.Background(), token); err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+	})
+
+	t.Run("not yet valid beyond the clock skew", func(t *testing.T) {
+		claims := iss.Claims("user-1", audience, time.Hour)
+		claims["nbf"] = time.Now().Add(time.Minute).Unix()
+		assertRejected(t, v, iss.Sign(t, "RS256", claims))
+	})
+
+	t.Run("without exp", func(t *testing.T) {
+		claims := iss.Claims("user-1", audience, time.Minute)
+		delete(claims, "exp")
+		assertRejected(t, v, iss.Sign(t, "RS256", claims))
+	})
+}
+
+func TestVerifyIssuerAndAudience(t *testing.T) {
+	iss := jwttest.NewIssuer(t)
+	v := newVerifier(t, iss, nil)
+
+	t.Run("wrong issuer", func(t *testing.T) {
+		claims := iss.Claims("user-1", audience, time.Minute)
+		claims["iss"] = "https://attacker.example"
+		assertRejected(t, v, iss.Sign(t, "RS256", claims))
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		assertRejected(t, v, iss.Sign(t, "RS256", iss.Claims("user-1", "other-api", time.Minute)))
+	})
+
+	t.Run("one of several audiences", func(t *testing.T) {
+		claims := iss.Claims("user-1", audience, time.Minute)
+		claims["aud"] = []string{"other-api", audience}
+		if _, err := v.Verify(context.Background(), iss.Sign(t, "RS256", claims)); err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+	})
+}
+
+func TestJWKSUnknownKeyRefreshIsRateLimited(t *testing.T) {
+	iss := jwttest.NewIssuer(t)
+	v := newVerifier(t, iss, nil)
+
+	if _, err := v.Verify(context.Background(), iss.Sign(t, "RS256", iss.Claims("user-1", audience, time.Minute))); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	token := gojwt.NewWithClaims(gojwt.SigningMethodRS256, iss.Claims("user-1", audience, time.Minute))
+	token.Header["kid"] = "unknown"
+	unknown, err := token.SigningString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The signature is never checked: the key lookup fails first
+	unknown += ".c2lnbmF0dXJl"
+
+	for range 5 {
+		assertRejected(t, v, unknown)
+	}
+	if got := iss.Fetches(); got != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1: unknown key IDs must not refetch within the minimum refresh interval", got)
+	}
+}
+
+func TestJWKSKeyRotation(t *testing.T) {
+	iss := jwttest.NewIssuer(t)
+	v := newVerifier(t, iss, func(cfg *config.AuthConfigs) { cfg.JWKSMinRefresh = 0 })
+
+	old := iss.Sign(t, "ES256", iss.Claims("user-1", audience, time.Minute))
+	if _, err := v.Verify(context.Background(), old); err != nil {
+		t.Fatalf("Verify before rotation: %v", err)
+	}
+
+	// The unknown key ID of the new key triggers a refetch
+	iss.Rotate(t)
+	rotated := iss.Sign(t, "ES256", iss.Claims("user-1", audience, time.Minute))
+	if _, err := v.Verify(context.Background(), rotated); err != nil {
+		t.Fatalf("Verify after rotation: %v", err)
+	}
+	if got := iss.Fetches(); got != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", got)
+	}
+
+	// The old key left the set
+	assertRejected(t, v, old)
+}
+
+// rsaModulus returns the modulus of the issuer's RSA key as published in its JWKS
+func rsaModulus(t *testing.T, iss *jwttest.Issuer) []byte {
+	t.Helper()
+
+	resp, err := http.Get(iss.JWKSURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range set.Keys {
+		if key.Kty == "RSA" {
+			n, err := base64.RawURLEncoding.DecodeString(key.N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return n
+		}
+	}
+	t.Fatal("JWKS has no RSA key")
+	return nil
+}
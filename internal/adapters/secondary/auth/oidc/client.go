@@ -0,0 +1,338 @@
+// Package oidc is an OpenID Connect relying party: it discovers the provider, builds
+// authorization-code requests with PKCE, exchanges and refreshes tokens and verifies
+// ID tokens.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/jwt"
+	"go-chi-boilerplate/internal/config"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// idTokenLeeway is the clock skew tolerated on ID token timestamps
+const idTokenLeeway = 30 * time.Second
+
+// Discovery holds the provider metadata served at /.well-known/openid-configuration
+type Discovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	UserinfoEndpoint      string   `json:"userinfo_endpoint,omitempty"`
+	EndSessionEndpoint    string   `json:"end_session_endpoint,omitempty"`
+	CodeChallengeMethods  []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// Tokens is a successful token endpoint response
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	IDToken      string
+	// Expiry is when the access token expires; zero when the provider didn't say
+	Expiry time.Time
+}
+
+// TokenError is an OAuth 2.0 error response of the token endpoint, e.g. invalid_grant
+// for a used or expired code or refresh token
+type TokenError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *TokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oidc token endpoint: %s: %s", e.Code, e.Description)
+	}
+	return "oidc token endpoint: " + e.Code
+}
+
+// Client talks to the provider at OIDC_ISSUER_URL. The provider metadata is fetched
+// on first use, so the service starts even while the provider is unreachable.
+type Client struct {
+	cfg    *config.OIDCConfigs
+	client *http.Client
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	discovery *Discovery
+	jwks      *jwt.JWKS
+	parser    *gojwt.Parser
+
+	// fetchMu serializes discovery so concurrent first calls trigger a single request
+	// without holding mu during it
+	fetchMu sync.Mutex
+}
+
+// New creates a Client for the configured provider
+func New(cfg *config.OIDCConfigs, logger *slog.Logger) *Client {
+	return &Client{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger,
+	}
+}
+
+// RolesClaim returns the claim path of the roles in ID tokens, see Session.Principal
+func (c *Client) RolesClaim() string {
+	return c.cfg.RolesClaim
+}
+
+// Discover returns the provider metadata, fetching it on the first call
+func (c *Client) Discover(ctx context.Context) (*Discovery, error) {
+	if d := c.cachedDiscovery(); d != nil {
+		return d, nil
+	}
+
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+	// Another caller may have fetched the document while this one waited
+	if d := c.cachedDiscovery(); d != nil {
+		return d, nil
+	}
+
+	wellKnown := strings.TrimSuffix(c.cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch oidc discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch oidc discovery document: %s responded %s", wellKnown, resp.Status)
+	}
+
+	var d Discovery
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&d); err != nil {
+		return nil, fmt.Errorf("failed to decode oidc discovery document: %w", err)
+	}
+	// The issuer must match exactly, otherwise tokens could come from another provider
+	if strings.TrimSuffix(d.Issuer, "/") != strings.TrimSuffix(c.cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("oidc discovery issuer %q does not match OIDC_ISSUER_URL %q", d.Issuer, c.cfg.IssuerURL)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("oidc discovery document lacks the authorization, token or jwks endpoint")
+	}
+	if len(d.CodeChallengeMethods) > 0 && !slices.Contains(d.CodeChallengeMethods, "S256") {
+		return nil, errors.New("oidc provider does not support PKCE with S256")
+	}
+
+	jwks := jwt.NewJWKS(d.JWKSURI, 15*time.Minute, time.Minute, c.logger)
+	parser := gojwt.NewParser(
+		gojwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "EdDSA"}),
+		gojwt.WithIssuer(d.Issuer),
+		gojwt.WithAudience(c.cfg.ClientID),
+		gojwt.WithLeeway(idTokenLeeway),
+		gojwt.WithExpirationRequired(),
+		gojwt.WithIssuedAt(),
+	)
+
+	c.mu.Lock()
+	c.discovery, c.jwks, c.parser = &d, jwks, parser
+	c.mu.Unlock()
+	c.logger.InfoContext(ctx, "oidc provider discovered", "issuer", d.Issuer)
+	return &d, nil
+}
+
+func (c *Client) cachedDiscovery() *Discovery {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.discovery
+}
+
+// AuthCodeURL returns the URL that starts an authorization-code flow with PKCE;
+// verifier is the code verifier later passed to Exchange
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	d, err := c.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(d.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid oidc authorization endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.cfg.ClientID)
+	q.Set("redirect_uri", c.cfg.RedirectURL)
+	q.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", Challenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange redeems an authorization code
+func (c *Client) Exchange(ctx context.Context, code, verifier string) (*Tokens, error) {
+	return c.token(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	})
+}
+
+// Refresh obtains new tokens with a refresh token; providers that don't rotate refresh
+// tokens leave RefreshToken empty
+func (c *Client) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	return c.token(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and, when nonce is not
+// empty, the nonce of an ID token and returns its claims
+func (c *Client) VerifyIDToken(ctx context.Context, raw, nonce string) (map[string]any, error) {
+	if _, err := c.Discover(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	parser, jwks := c.parser, c.jwks
+	c.mu.Unlock()
+
+	claims := gojwt.MapClaims{}
+	_, err := parser.ParseWithClaims(raw, claims, func(t *gojwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return jwks.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if nonce != "" {
+		if got, _ := claims["nonce"].(string); got != nonce {
+			return nil, errors.New("invalid id token: nonce does not match")
+		}
+	}
+	// With several audiences the token must have been issued to this client
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != c.cfg.ClientID {
+			return nil, errors.New("invalid id token: azp does not match the client ID")
+		}
+	}
+	return claims, nil
+}
+
+// EndSessionURL returns the provider's RP-initiated logout URL, or "" when the
+// provider has no end_session_endpoint
+func (c *Client) EndSessionURL(ctx context.Context, idTokenHint string) (string, error) {
+	d, err := c.Discover(ctx)
+	if err != nil || d.EndSessionEndpoint == "" {
+		return "", err
+	}
+
+	u, err := url.Parse(d.EndSessionEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid oidc end session endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("client_id", c.cfg.ClientID)
+	if idTokenHint != "" {
+		q.Set("id_token_hint", idTokenHint)
+	}
+	if c.cfg.PostLogoutRedirectURL != "" {
+		q.Set("post_logout_redirect_uri", c.cfg.PostLogoutRedirectURL)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func (c *Client) token(ctx context.Context, form url.Values) (*Tokens, error) {
+	d, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Confidential clients authenticate with client_secret_basic, public ones send
+	// only their ID and rely on PKCE
+	if c.cfg.ClientSecret == "" {
+		form.Set("client_id", c.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call oidc token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read oidc token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		tokenErr := &TokenError{}
+		if json.Unmarshal(body, tokenErr) != nil || tokenErr.Code == "" {
+			return nil, fmt.Errorf("oidc token endpoint responded %s", resp.Status)
+		}
+		return nil, tokenErr
+	}
+
+	var raw struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token"`
+		IDToken      string `json:"id_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode oidc token response: %w", err)
+	}
+	if raw.AccessToken == "" || !strings.EqualFold(raw.TokenType, "Bearer") {
+		return nil, fmt.Errorf("oidc token response has no bearer access token (token_type %q)", raw.TokenType)
+	}
+
+	tokens := &Tokens{AccessToken: raw.AccessToken, RefreshToken: raw.RefreshToken, IDToken: raw.IDToken}
+	if raw.ExpiresIn > 0 {
+		tokens.Expiry = time.Now().Add(time.Duration(raw.ExpiresIn) * time.Second)
+	}
+	return tokens, nil
+}
+
+// RandomString returns 32 random bytes, base64url encoded; use it for state, nonce
+// and PKCE code verifiers
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Challenge returns the S256 PKCE code challenge of verifier
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
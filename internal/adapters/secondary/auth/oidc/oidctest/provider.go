@@ -0,0 +1,275 @@
+// Package oidctest runs a local OpenID Connect provider for tests. It signs every user
+// in without a login page: /authorize redirects straight back with a code.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// ClientID and ClientSecret are the only registered client; pass them as
+	// OIDC_CLIENT_ID and OIDC_CLIENT_SECRET
+	ClientID     = "oidctest-client"
+	ClientSecret = "oidctest-secret"
+
+	keyID = "oidctest-key"
+)
+
+// Provider is a fake OIDC provider; pass URL as OIDC_ISSUER_URL
+type Provider struct {
+	URL string
+
+	mu             sync.Mutex
+	user           map[string]any
+	accessTokenTTL time.Duration
+	refreshes      int
+	logouts        int
+
+	key     *rsa.PrivateKey
+	server  *httptest.Server
+	codes   map[string]authRequest
+	refresh map[string]bool
+}
+
+type authRequest struct {
+	redirectURI string
+	nonce       string
+	challenge   string
+}
+
+// NewProvider starts a Provider that is closed when the test ends
+func NewProvider(tb testing.TB) *Provider {
+	tb.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		tb.Fatalf("oidctest: failed to generate key: %v", err)
+	}
+
+	p := &Provider{
+		user:           map[string]any{"sub": "user-1", "email": "user@example.com", "name": "Test User"},
+		accessTokenTTL: time.Hour,
+		key:            key,
+		codes:          make(map[string]authRequest),
+		refresh:        make(map[string]bool),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.serveDiscovery)
+	mux.HandleFunc("GET /authorize", p.serveAuthorize)
+	mux.HandleFunc("POST /token", p.serveToken)
+	mux.HandleFunc("GET /jwks", p.serveJWKS)
+	mux.HandleFunc("GET /logout", p.serveLogout)
+	p.server = httptest.NewServer(mux)
+	p.URL = p.server.URL
+	tb.Cleanup(p.server.Close)
+
+	return p
+}
+
+// SetUser replaces the claims of the user that signs in from now on; sub is required.
+// The default user is user-1 (user@example.com).
+func (p *Provider) SetUser(claims map[string]any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.user = claims
+}
+
+// SetAccessTokenTTL changes the lifetime of access tokens issued from now on; the
+// default is an hour
+func (p *Provider) SetAccessTokenTTL(ttl time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.accessTokenTTL = ttl
+}
+
+// Refreshes returns how often a refresh token was redeemed
+func (p *Provider) Refreshes() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refreshes
+}
+
+// Logouts returns how often the end session endpoint was called
+func (p *Provider) Logouts() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.logouts
+}
+
+func (p *Provider) serveDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.URL,
+		"authorization_endpoint":                p.URL + "/authorize",
+		"token_endpoint":                        p.URL + "/token",
+		"jwks_uri":                              p.URL + "/jwks",
+		"end_session_endpoint":                  p.URL + "/logout",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) serveAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != ClientID || q.Get("response_type") != "code" || q.Get("redirect_uri") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = authRequest{redirectURI: q.Get("redirect_uri"), nonce: q.Get("nonce"), challenge: q.Get("code_challenge")}
+	p.mu.Unlock()
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) serveToken(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if ok {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+	}
+	if !ok || id != ClientID || subtle.ConstantTimeCompare([]byte(secret), []byte(ClientSecret)) != 1 {
+		tokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var nonce string
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		req, found := p.codes[r.PostForm.Get("code")]
+		delete(p.codes, r.PostForm.Get("code"))
+		if !found || req.redirectURI != r.PostForm.Get("redirect_uri") {
+			tokenError(w, http.StatusBadRequest, "invalid_grant")
+			return
+		}
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != req.challenge {
+			tokenError(w, http.StatusBadRequest, "invalid_grant")
+			return
+		}
+		nonce = req.nonce
+
+	case "refresh_token":
+		// Refresh tokens are rotated: each one works once
+		if !p.refresh[r.PostForm.Get("refresh_token")] {
+			tokenError(w, http.StatusBadRequest, "invalid_grant")
+			return
+		}
+		delete(p.refresh, r.PostForm.Get("refresh_token"))
+		p.refreshes++
+
+	default:
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	idToken, err := p.idToken(nonce)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	refreshToken := randomString()
+	p.refresh[refreshToken] = true
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token":  randomString(),
+		"token_type":    "Bearer",
+		"expires_in":    int(p.accessTokenTTL.Seconds()),
+		"refresh_token": refreshToken,
+		"id_token":      idToken,
+	})
+}
+
+// idToken must be called with mu held
+func (p *Provider) idToken(nonce string) (string, error) {
+	now := time.Now()
+	claims := gojwt.MapClaims{}
+	maps.Copy(claims, p.user)
+	claims["iss"] = p.URL
+	claims["aud"] = ClientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(time.Hour).Unix()
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	token := gojwt.NewWithClaims(gojwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(p.key)
+}
+
+func (p *Provider) serveJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA", "kid": keyID, "use": "sig", "alg": "RS256",
+		"n": base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+		"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+	}}})
+}
+
+func (p *Provider) serveLogout(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.logouts++
+	p.mu.Unlock()
+
+	if redirect := r.URL.Query().Get("post_logout_redirect_uri"); redirect != "" {
+		http.Redirect(w, r, redirect, http.StatusFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func tokenError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
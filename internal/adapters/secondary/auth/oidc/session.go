@@ -0,0 +1,70 @@
+package oidc
+
+import (
+	"go-chi-boilerplate/internal/core/auth"
+	"time"
+)
+
+// refreshAhead refreshes access tokens this long before they expire
+const refreshAhead = 30 * time.Second
+
+// Session is what a signed-in browser session keeps about the user
+type Session struct {
+	Claims       map[string]any `json:"claims"`
+	IDToken      string         `json:"id_token"`
+	AccessToken  string         `json:"access_token"`
+	RefreshToken string         `json:"refresh_token,omitempty"`
+	// Expiry is when the access token expires
+	Expiry    time.Time `json:"expiry,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewSession starts a session from the tokens of a login and the verified ID token claims
+func NewSession(tokens *Tokens, claims map[string]any) *Session {
+	s := &Session{CreatedAt: time.Now()}
+	s.Update(tokens, claims)
+	return s
+}
+
+// Update applies refreshed tokens; claims may be nil when no new ID token was issued
+func (s *Session) Update(tokens *Tokens, claims map[string]any) {
+	s.AccessToken = tokens.AccessToken
+	s.Expiry = tokens.Expiry
+	if tokens.RefreshToken != "" {
+		s.RefreshToken = tokens.RefreshToken
+	}
+	if tokens.IDToken != "" && claims != nil {
+		s.IDToken = tokens.IDToken
+		s.Claims = claims
+	}
+}
+
+// NeedsRefresh reports whether the access token expires soon and can be refreshed
+func (s *Session) NeedsRefresh(now time.Time) bool {
+	return s.RefreshToken != "" && !s.Expiry.IsZero() && now.Add(refreshAhead).After(s.Expiry)
+}
+
+// User returns the signed-in user
+func (s *Session) User() *auth.User {
+	u := &auth.User{Claims: s.Claims}
+	u.Subject, _ = s.Claims["sub"].(string)
+	u.Issuer, _ = s.Claims["iss"].(string)
+	u.Email, _ = s.Claims["email"].(string)
+	u.Name, _ = s.Claims["name"].(string)
+	return u
+}
+
+// Principal returns the user as the principal of a request, so the authorization
+// middlewares apply to browser sessions as well; rolesClaim is the dotted path of the
+// roles claim, as for bearer tokens
+func (s *Session) Principal(rolesClaim string) *auth.Principal {
+	u := s.User()
+	return &auth.Principal{
+		Subject:  u.Subject,
+		Issuer:   u.Issuer,
+		Audience: auth.StringList(s.Claims["aud"]),
+		Roles:    auth.StringList(auth.ClaimAt(s.Claims, rolesClaim)),
+		Method:   "oidc",
+		Claims:   s.Claims,
+	}
+}
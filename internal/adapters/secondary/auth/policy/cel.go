@@ -0,0 +1,116 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"go-chi-boilerplate/internal/core/auth"
+
+	"github.com/google/cel-go/cel"
+)
+
+// CEL allows an action when a role grants it, as RBAC does, or when the expression of
+// a rule matching the action evaluates to true. Expressions see principal (subject,
+// issuer, audience, scopes, roles, method and claims), action and resource, e.g.
+// resource.owner == principal.subject || "support" in principal.roles.
+type CEL struct {
+	rbac  *RBAC
+	rules []celRule
+}
+
+type celRule struct {
+	Rule
+	program cel.Program
+}
+
+// NewCEL compiles the rules; a rule fails unless it yields a bool, or a dyn value such
+// as a claim, which must then be a bool at evaluation time
+func NewCEL(roles map[string][]string, rules []Rule) (*CEL, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("principal", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("action", cel.StringType),
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &CEL{rbac: NewRBAC(roles)}
+	for _, rule := range rules {
+		ast, issues := env.Compile(rule.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("policy rule %q: %w", rule.Name, issues.Err())
+		}
+		if out := ast.OutputType(); out != cel.BoolType && out != cel.DynType {
+			return nil, fmt.Errorf("policy rule %q must evaluate to a bool, got %s", rule.Name, ast.OutputType())
+		}
+
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("policy rule %q: %w", rule.Name, err)
+		}
+		e.rules = append(e.rules, celRule{Rule: rule, program: program})
+	}
+	return e, nil
+}
+
+// Evaluate implements ports.PolicyEngine
+func (e *CEL) Evaluate(ctx context.Context, req auth.Request) (auth.Decision, error) {
+	decision, err := e.rbac.Evaluate(ctx, req)
+	if err != nil || decision.Allowed || req.Principal == nil {
+		return decision, err
+	}
+
+	vars := map[string]any{
+		"principal": principalVars(req.Principal),
+		"action":    req.Action,
+		"resource":  resourceVars(req.Resource),
+	}
+	for _, rule := range e.rules {
+		if !matchAction(rule.Action, req.Action) {
+			continue
+		}
+
+		out, _, err := rule.program.ContextEval(ctx, vars)
+		if err != nil {
+			return auth.Decision{}, fmt.Errorf("policy rule %q: %w", rule.Name, err)
+		}
+		allowed, ok := out.Value().(bool)
+		if !ok {
+			return auth.Decision{}, fmt.Errorf("policy rule %q evaluated to %v, not a bool", rule.Name, out.Type())
+		}
+		if allowed {
+			return auth.Allow("rule " + rule.Name), nil
+		}
+	}
+	return auth.Deny("no role or rule grants " + req.Action), nil
+}
+
+func principalVars(p *auth.Principal) map[string]any {
+	claims := p.Claims
+	if claims == nil {
+		claims = map[string]any{}
+	}
+	return map[string]any{
+		"subject":  p.Subject,
+		"issuer":   p.Issuer,
+		"audience": nonNil(p.Audience),
+		"scopes":   nonNil(p.Scopes),
+		"roles":    nonNil(p.Roles),
+		"method":   p.Method,
+		"claims":   claims,
+	}
+}
+
+func resourceVars(resource map[string]any) map[string]any {
+	if resource == nil {
+		return map[string]any{}
+	}
+	return resource
+}
+
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/core/ports"
+	"os"
+	"strings"
+)
+
+// File is the format of AUTH_POLICY_FILE
+type File struct {
+	// Roles maps each role to the actions it grants; "*" grants every action and
+	// "orders:*" every action starting with "orders:"
+	Roles map[string][]string `json:"roles"`
+	// Rules are CEL expressions evaluated for the actions they match
+	Rules []Rule `json:"rules"`
+}
+
+// Rule allows an action when its CEL expression evaluates to true
+type Rule struct {
+	Name string `json:"name"`
+	// Action is matched like the actions of a role
+	Action     string `json:"action"`
+	Expression string `json:"expression"`
+}
+
+// New creates the PolicyEngine selected by AUTH_POLICY_ENGINE from AUTH_POLICY_FILE;
+// without a file no role grants anything
+func New(cfg *config.AuthConfigs) (ports.PolicyEngine, error) {
+	var f File
+	if cfg.PolicyFile != "" {
+		raw, err := os.ReadFile(cfg.PolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy file: %w", err)
+		}
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse policy file %s: %w", cfg.PolicyFile, err)
+		}
+	}
+
+	switch cfg.PolicyEngine {
+	case "rbac":
+		if len(f.Rules) > 0 {
+			return nil, fmt.Errorf("policy file %s has rules, which need AUTH_POLICY_ENGINE=cel", cfg.PolicyFile)
+		}
+		return NewRBAC(f.Roles), nil
+	case "cel":
+		return NewCEL(f.Roles, f.Rules)
+	default:
+		return nil, fmt.Errorf("unknown policy engine %q", cfg.PolicyEngine)
+	}
+}
+
+// matchAction reports whether pattern covers action
+func matchAction(pattern, action string) bool {
+	if pattern == "*" || pattern == action {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "*")
+	return ok && strings.HasPrefix(action, prefix)
+}
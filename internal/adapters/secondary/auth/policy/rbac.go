@@ -0,0 +1,32 @@
+package policy
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/core/auth"
+)
+
+// RBAC allows an action when one of the principal's roles grants it
+type RBAC struct {
+	roles map[string][]string
+}
+
+// NewRBAC creates an RBAC engine from role to granted actions
+func NewRBAC(roles map[string][]string) *RBAC {
+	return &RBAC{roles: roles}
+}
+
+// Evaluate implements ports.PolicyEngine
+func (e *RBAC) Evaluate(ctx context.Context, req auth.Request) (auth.Decision, error) {
+	if req.Principal == nil {
+		return auth.Deny("unauthenticated"), nil
+	}
+
+	for _, role := range req.Principal.Roles {
+		for _, pattern := range e.roles[role] {
+			if matchAction(pattern, req.Action) {
+				return auth.Allow("role " + role), nil
+			}
+		}
+	}
+	return auth.Deny("no role grants " + req.Action), nil
+}
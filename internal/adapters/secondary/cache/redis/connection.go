@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/core/ports"
+	"log/slog"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type RedisCache struct {
+	Client *goredis.Client
+	Logger *slog.Logger
+}
+
+// New creates a new Redis connection
+func New(cfg *config.RedisConfigs, logger *slog.Logger) (*RedisCache, error) {
+	client := goredis.NewClient(&goredis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+		PoolSize: cfg.PoolSize,
+	})
+
+	// Use a short timeout for ping to avoid blocking startup
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		logger.Error("failed to ping redis", "addr", cfg.Addr, "error", err)
+		client.Close()
+		return nil, err
+	}
+
+	logger.Info("connected to Redis", "addr", cfg.Addr, "db", cfg.DB)
+
+	return &RedisCache{
+		Client: client,
+		Logger: logger,
+	}, nil
+}
+
+// Get returns the value stored under key or ports.ErrCacheMiss
+func (r *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	val, err := r.Client.Get(ctx, key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, ports.ErrCacheMiss
+	}
+	return val, err
+}
+
+// Set stores value under key with the given ttl
+func (r *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.Client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete removes the given keys
+func (r *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.Client.Del(ctx, keys...).Err()
+}
+
+// Ping checks that Redis is reachable
+func (r *RedisCache) Ping(ctx context.Context) error {
+	return r.Client.Ping(ctx).Err()
+}
+
+// Close closes the Redis connection
+func (r *RedisCache) Close() {
+	if err := r.Client.Close(); err != nil {
+		r.Logger.Error("failed to close redis connection", "error", err)
+	} else {
+		r.Logger.Info("redis connection closed")
+	}
+}
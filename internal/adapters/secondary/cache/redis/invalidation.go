@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+)
+
+type invalidationMessage struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// Invalidator fans cache invalidations out to other replicas over Redis pub/sub
+type Invalidator struct {
+	cache    *RedisCache
+	channel  string
+	originID string
+	logger   *slog.Logger
+}
+
+// NewInvalidator creates an Invalidator publishing on the given channel
+func NewInvalidator(cache *RedisCache, channel string, logger *slog.Logger) *Invalidator {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	return &Invalidator{
+		cache:    cache,
+		channel:  channel,
+		originID: hex.EncodeToString(id),
+		logger:   logger,
+	}
+}
+
+// Publish announces to other replicas that the given keys changed
+func (i *Invalidator) Publish(ctx context.Context, keys ...string) error {
+	payload, err := json.Marshal(invalidationMessage{Origin: i.originID, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return i.cache.Client.Publish(ctx, i.channel, payload).Err()
+}
+
+// Subscribe calls handler for invalidations published by other replicas until ctx is done
+func (i *Invalidator) Subscribe(ctx context.Context, handler func(keys []string)) {
+	pubsub := i.cache.Client.Subscribe(ctx, i.channel)
+
+	go func() {
+		defer pubsub.Close()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+
+				var m invalidationMessage
+				if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+					i.logger.Warn("invalid cache invalidation message", "error", err)
+					continue
+				}
+				if m.Origin == i.originID {
+					continue
+				}
+				handler(m.Keys)
+			}
+		}
+	}()
+}
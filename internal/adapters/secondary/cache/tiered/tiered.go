@@ -0,0 +1,117 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"go-chi-boilerplate/internal/adapters/secondary/cache/local"
+	"go-chi-boilerplate/internal/core/ports"
+	"go-chi-boilerplate/internal/meta"
+	"log/slog"
+	"time"
+)
+
+// Broadcaster distributes key invalidations between replicas
+type Broadcaster interface {
+	Publish(ctx context.Context, keys ...string) error
+	Subscribe(ctx context.Context, handler func(keys []string))
+}
+
+// Cache is a two-tier cache: an optional in-process LRU in front of a shared remote cache.
+// Writes and deletes are broadcast so other replicas drop their stale local copies.
+type Cache struct {
+	local  *local.LRU
+	remote ports.Cache
+	bus    Broadcaster
+	logger *slog.Logger
+}
+
+// New creates a tiered Cache. local and bus may be nil, in which case only the remote tier is used.
+// Remote invalidations are applied until ctx is done.
+func New(ctx context.Context, l *local.LRU, remote ports.Cache, bus Broadcaster, logger *slog.Logger) *Cache {
+	c := &Cache{
+		local:  l,
+		remote: remote,
+		bus:    bus,
+		logger: logger,
+	}
+
+	if l != nil && bus != nil {
+		bus.Subscribe(ctx, func(keys []string) {
+			l.Delete(keys...)
+			meta.CacheInvalidationsTotal.WithLabelValues("remote").Add(float64(len(keys)))
+			meta.CacheLocalEntries.Set(float64(l.Len()))
+		})
+	}
+
+	return c
+}
+
+// Get looks the key up in the local tier first and falls back to the remote tier
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	if c.local != nil {
+		if val, ok := c.local.Get(key); ok {
+			meta.CacheRequestsTotal.WithLabelValues("local", "hit").Inc()
+			return val, nil
+		}
+		meta.CacheRequestsTotal.WithLabelValues("local", "miss").Inc()
+	}
+
+	val, err := c.remote.Get(ctx, key)
+	switch {
+	case errors.Is(err, ports.ErrCacheMiss):
+		meta.CacheRequestsTotal.WithLabelValues("remote", "miss").Inc()
+		return nil, err
+	case err != nil:
+		meta.CacheRequestsTotal.WithLabelValues("remote", "error").Inc()
+		return nil, err
+	}
+	meta.CacheRequestsTotal.WithLabelValues("remote", "hit").Inc()
+
+	if c.local != nil {
+		c.local.Set(key, val, 0)
+		meta.CacheLocalEntries.Set(float64(c.local.Len()))
+	}
+
+	return val, nil
+}
+
+// Set writes through both tiers and invalidates the key on other replicas
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+
+	if c.local != nil {
+		c.local.Set(key, value, ttl)
+		meta.CacheLocalEntries.Set(float64(c.local.Len()))
+		c.broadcast(ctx, key)
+	}
+
+	return nil
+}
+
+// Delete removes the keys from both tiers and invalidates them on other replicas
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if err := c.remote.Delete(ctx, keys...); err != nil {
+		return err
+	}
+
+	if c.local != nil {
+		c.local.Delete(keys...)
+		meta.CacheInvalidationsTotal.WithLabelValues("local").Add(float64(len(keys)))
+		meta.CacheLocalEntries.Set(float64(c.local.Len()))
+		c.broadcast(ctx, keys...)
+	}
+
+	return nil
+}
+
+func (c *Cache) broadcast(ctx context.Context, keys ...string) {
+	if c.bus == nil {
+		return
+	}
+	// A failed broadcast only delays convergence until the local TTL expires
+	if err := c.bus.Publish(ctx, keys...); err != nil {
+		c.logger.Warn("failed to broadcast cache invalidation", "keys", keys, "error", err)
+	}
+}
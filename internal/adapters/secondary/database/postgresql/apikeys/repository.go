@@ -0,0 +1,127 @@
+package apikeys
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/core/apperrors"
+	"go-chi-boilerplate/internal/core/domain"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Repository stores API keys in the api_keys table (see migrations); it implements
+// ports.APIKeyRepository
+type Repository struct {
+	db *sql.DB
+}
+
+// New creates a Repository on db
+func New(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+const columns = `id, name, prefix, hash, scopes, created_at, expires_at, revoked_at, last_used_at`
+
+// Create inserts key; a duplicate prefix returns an apperrors.Conflict error
+func (r *Repository) Create(ctx context.Context, key *domain.APIKey) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO api_keys (id, name, prefix, hash, scopes, created_at, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		key.ID, key.Name, key.Prefix, key.Hash, pq.Array(key.Scopes), key.CreatedAt, key.ExpiresAt,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return apperrors.Conflict("api_key_exists", "an API key with this prefix already exists").Wrap(err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// GetByPrefix returns the key with the given lookup prefix
+func (r *Repository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+columns+` FROM api_keys WHERE prefix = $1`, prefix)
+
+	key, err := scan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.NotFound("api_key_not_found", "the API key does not exist")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return key, nil
+}
+
+// List returns every key, newest first
+func (r *Repository) List(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+columns+` FROM api_keys ORDER BY created_at DESC, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		key, err := scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke marks the key as revoked; revoking it again keeps the first revocation time
+func (r *Repository) Revoke(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1`, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.NotFound("api_key_not_found", "the API key does not exist")
+	}
+	return nil
+}
+
+// TouchLastUsed records that the key was used at
+func (r *Repository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET last_used_at = $2 WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2)`,
+		id, at,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update api key last use: %w", err)
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scan(row scanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var expiresAt, revokedAt, lastUsedAt sql.NullTime
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, pq.Array(&key.Scopes),
+		&key.CreatedAt, &expiresAt, &revokedAt, &lastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	key.ExpiresAt = nullTime(expiresAt)
+	key.RevokedAt = nullTime(revokedAt)
+	key.LastUsedAt = nullTime(lastUsedAt)
+	return &key, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
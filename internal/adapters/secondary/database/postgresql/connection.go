@@ -58,6 +58,11 @@ func New(cfg *config.DatabaseConfigs, logger *slog.Logger) (*PostgresDB, error)
 	}, nil
 }
 
+// Ping checks that the database is reachable
+func (p *PostgresDB) Ping(ctx context.Context) error {
+	return p.DB.PingContext(ctx)
+}
+
 // Close closes the DB connection
 func (p *PostgresDB) Close() {
 	if err := p.DB.Close(); err != nil {
@@ -9,11 +9,11 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-func RunMigrations(db *PostgresDB, migrationsPath string, logger *slog.Logger) error {
+func newMigrate(db *PostgresDB, migrationsPath string, logger *slog.Logger) (*migrate.Migrate, error) {
 	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
 	if err != nil {
 		logger.Error("failed to create migration driver", "error", err)
-		return fmt.Errorf("failed to create migration driver: %w", err)
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
@@ -23,7 +23,16 @@ func RunMigrations(db *PostgresDB, migrationsPath string, logger *slog.Logger) e
 	)
 	if err != nil {
 		logger.Error("failed to create migrate instance", "error", err)
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	return m, nil
+}
+
+// RunMigrations applies all pending up migrations
+func RunMigrations(db *PostgresDB, migrationsPath string, logger *slog.Logger) error {
+	m, err := newMigrate(db, migrationsPath, logger)
+	if err != nil {
+		return err
 	}
 
 	if err := m.Up(); err != nil {
@@ -38,3 +47,37 @@ func RunMigrations(db *PostgresDB, migrationsPath string, logger *slog.Logger) e
 	logger.Info("database migrations applied successfully")
 	return nil
 }
+
+// RollbackMigrations applies the given number of down migrations
+func RollbackMigrations(db *PostgresDB, migrationsPath string, steps int, logger *slog.Logger) error {
+	m, err := newMigrate(db, migrationsPath, logger)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Steps(-steps); err != nil {
+		if err == migrate.ErrNoChange {
+			logger.Info("no migrations to roll back")
+			return nil
+		}
+		logger.Error("failed to roll back migrations", "error", err)
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+
+	logger.Info("database migrations rolled back", "steps", steps)
+	return nil
+}
+
+// MigrationVersion returns the current schema version and whether the last migration failed midway
+func MigrationVersion(db *PostgresDB, migrationsPath string, logger *slog.Logger) (uint, bool, error) {
+	m, err := newMigrate(db, migrationsPath, logger)
+	if err != nil {
+		return 0, false, err
+	}
+
+	version, dirty, err := m.Version()
+	if err == migrate.ErrNilVersion {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
@@ -0,0 +1,264 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/core/ports"
+	"go-chi-boilerplate/internal/meta"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// leaderLockKey is the Postgres advisory lock held by the replica that dispatches
+const leaderLockKey int64 = 0x6f7574626f78 // "outbox"
+
+// claimLease is how long a claimed message is withheld from other dispatches; it must
+// outlast the delivery of a whole batch
+const claimLease = 5 * time.Minute
+
+type message struct {
+	id       int64
+	topic    string
+	payload  []byte
+	attempts int
+}
+
+// Dispatcher delivers pending outbox messages with retries, exponential backoff and
+// dead-lettering. Only the replica holding the advisory lock dispatches. Delivery is
+// at-least-once: a crash between delivering and recording the outcome redelivers the
+// message after claimLease.
+type Dispatcher struct {
+	db        *postgresql.PostgresDB
+	cfg       *config.OutboxConfigs
+	logger    *slog.Logger
+	handlers  map[string]Handler
+	publisher ports.EventPublisher
+}
+
+// NewDispatcher creates a Dispatcher; register handlers before calling Run
+func NewDispatcher(db *postgresql.PostgresDB, cfg *config.OutboxConfigs, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		db:       db,
+		cfg:      cfg,
+		logger:   logger,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Register sets the handler for a topic
+func (d *Dispatcher) Register(topic string, h Handler) {
+	d.handlers[topic] = h
+}
+
+// SetPublisher sends messages of topics without a registered handler to publisher
+func (d *Dispatcher) SetPublisher(publisher ports.EventPublisher) {
+	d.publisher = publisher
+}
+
+// Run competes for leadership and dispatches while leader, until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.logger.Info("outbox dispatcher started", "poll_interval", d.cfg.PollInterval)
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.lead(ctx, ticker); err != nil && ctx.Err() == nil {
+			d.logger.Error("outbox dispatcher error", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			d.logger.Info("outbox dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead takes the advisory lock on a dedicated connection and dispatches until the
+// connection fails or ctx is done. The lock is released with the connection, so a
+// crashed leader is replaced as soon as Postgres notices.
+func (d *Dispatcher) lead(ctx context.Context, ticker *time.Ticker) error {
+	conn, err := d.db.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get leader connection: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, leaderLockKey).Scan(&acquired); err != nil {
+		return fmt.Errorf("failed to acquire outbox lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+
+	d.logger.Info("outbox dispatcher acquired leadership")
+	meta.OutboxLeader.Set(1)
+	defer func() {
+		meta.OutboxLeader.Set(0)
+		// Use a fresh context so the lock is released even on shutdown
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_, _ = conn.ExecContext(unlockCtx, `SELECT pg_advisory_unlock($1)`, leaderLockKey)
+	}()
+
+	for {
+		if err := conn.PingContext(ctx); err != nil {
+			return fmt.Errorf("lost outbox leader connection: %w", err)
+		}
+
+		// Drain full batches back to back, then wait for the next tick
+		for {
+			n, err := d.dispatchBatch(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					d.logger.Error("failed to dispatch outbox batch", "error", err)
+				}
+				break
+			}
+			if n < d.cfg.BatchSize {
+				break
+			}
+		}
+		d.updatePending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchBatch claims due messages and delivers them one by one. Claiming moves
+// available_at past claimLease in the same statement that selects the batch, so no row
+// lock or transaction is held while handlers talk to the network, and a message whose
+// outcome is never recorded becomes due again once the lease ends.
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	rows, err := d.db.DB.QueryContext(ctx, `
+		UPDATE outbox_messages
+		SET available_at = now() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id
+			FROM outbox_messages
+			WHERE status = 'pending' AND available_at <= now()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, topic, payload, attempts`, d.cfg.BatchSize, claimLease.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	var batch []message
+	for rows.Next() {
+		var m message
+		if err := rows.Scan(&m.id, &m.topic, &m.payload, &m.attempts); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// RETURNING does not keep the order of the subquery
+	slices.SortFunc(batch, func(a, b message) int { return cmp.Compare(a.id, b.id) })
+
+	for _, m := range batch {
+		if ctx.Err() != nil {
+			// Unsent messages of the batch are due again when their lease ends
+			return len(batch), ctx.Err()
+		}
+		if err := d.deliver(ctx, m); err != nil {
+			d.logger.Error("failed to record outbox delivery, the message will be redelivered",
+				"id", m.id, "topic", m.topic, "error", err,
+			)
+		}
+	}
+	return len(batch), nil
+}
+
+// deliver hands m to its handler and records the outcome
+func (d *Dispatcher) deliver(ctx context.Context, m message) error {
+	start := time.Now()
+	deliveryErr := d.handle(ctx, m)
+	meta.OutboxDeliveryDuration.WithLabelValues(m.topic).Observe(time.Since(start).Seconds())
+
+	attempts := m.attempts + 1
+
+	if deliveryErr == nil {
+		meta.OutboxMessagesTotal.WithLabelValues(m.topic, "delivered").Inc()
+		_, err := d.db.DB.ExecContext(ctx, `
+			UPDATE outbox_messages
+			SET status = 'delivered', attempts = $2, delivered_at = now(), last_error = NULL
+			WHERE id = $1`, m.id, attempts)
+		return err
+	}
+
+	if attempts >= d.cfg.MaxAttempts {
+		meta.OutboxMessagesTotal.WithLabelValues(m.topic, "dead").Inc()
+		d.logger.Error("outbox message dead-lettered",
+			"id", m.id, "topic", m.topic, "attempts", attempts, "error", deliveryErr,
+		)
+		_, err := d.db.DB.ExecContext(ctx, `
+			UPDATE outbox_messages
+			SET status = 'dead', attempts = $2, last_error = $3
+			WHERE id = $1`, m.id, attempts, deliveryErr.Error())
+		return err
+	}
+
+	delay := d.backoff(attempts)
+	meta.OutboxMessagesTotal.WithLabelValues(m.topic, "retry").Inc()
+	d.logger.Warn("outbox delivery failed, will retry",
+		"id", m.id, "topic", m.topic, "attempts", attempts, "retry_in", delay, "error", deliveryErr,
+	)
+	_, err := d.db.DB.ExecContext(ctx, `
+		UPDATE outbox_messages
+		SET attempts = $2, last_error = $3, available_at = now() + make_interval(secs => $4)
+		WHERE id = $1`, m.id, attempts, deliveryErr.Error(), delay.Seconds())
+	return err
+}
+
+func (d *Dispatcher) handle(ctx context.Context, m message) error {
+	if h, ok := d.handlers[m.topic]; ok {
+		return h(ctx, m.payload)
+	}
+	if d.publisher != nil {
+		return d.publisher.Publish(ctx, m.topic, m.payload)
+	}
+	return errors.New("no handler registered for topic " + m.topic)
+}
+
+// backoff doubles the base delay per attempt up to the maximum, with up to 20% jitter
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BaseBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.MaxBackoff {
+		delay = d.cfg.MaxBackoff
+	}
+	return delay + time.Duration(rand.Int64N(int64(delay)/5+1))
+}
+
+func (d *Dispatcher) updatePending(ctx context.Context) {
+	var pending int64
+	if err := d.db.DB.QueryRowContext(ctx,
+		`SELECT count(*) FROM outbox_messages WHERE status = 'pending'`,
+	).Scan(&pending); err != nil {
+		return
+	}
+	meta.OutboxPendingMessages.Set(float64(pending))
+}
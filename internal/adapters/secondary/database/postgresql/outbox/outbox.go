@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-chi-boilerplate/internal/core/domain"
+	"go-chi-boilerplate/internal/core/ports"
+)
+
+// TopicEmail is the topic of messages delivered to the Mailer
+const TopicEmail = "email"
+
+// Execer is satisfied by *sql.Tx and *sql.DB; pass the transaction that writes
+// the business data so the message is committed atomically with it
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Handler delivers the payload of an outbox message
+type Handler func(ctx context.Context, payload []byte) error
+
+// Enqueue stores a message for the dispatcher to deliver once tx commits
+func Enqueue(ctx context.Context, tx Execer, topic string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox payload: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO outbox_messages (topic, payload) VALUES ($1, $2)`,
+		topic, data,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue outbox message: %w", err)
+	}
+	return nil
+}
+
+// EnqueueEmail stores an email for delivery through the Mailer once tx commits
+func EnqueueEmail(ctx context.Context, tx Execer, email *domain.Email) error {
+	return Enqueue(ctx, tx, TopicEmail, email)
+}
+
+// EmailHandler delivers email messages with the given Mailer
+func EmailHandler(mailer ports.Mailer) Handler {
+	return func(ctx context.Context, payload []byte) error {
+		var email domain.Email
+		if err := json.Unmarshal(payload, &email); err != nil {
+			return fmt.Errorf("invalid email payload: %w", err)
+		}
+		return mailer.Send(ctx, &email)
+	}
+}
+
+// PublisherHandler delivers messages of a topic to the event publisher
+func PublisherHandler(publisher ports.EventPublisher, topic string) Handler {
+	return func(ctx context.Context, payload []byte) error {
+		return publisher.Publish(ctx, topic, payload)
+	}
+}
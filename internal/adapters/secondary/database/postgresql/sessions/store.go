@@ -0,0 +1,87 @@
+package sessions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/core/domain"
+	"go-chi-boilerplate/internal/core/ports"
+	"log/slog"
+	"time"
+)
+
+// Store keeps sessions in the sessions table (see migrations); it implements
+// ports.SessionStore
+type Store struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// New creates a Store on db
+func New(db *sql.DB, logger *slog.Logger) *Store {
+	return &Store{db: db, logger: logger}
+}
+
+// Get returns the session stored under id unless it has expired
+func (s *Store) Get(ctx context.Context, id string) (*domain.Session, error) {
+	sess := domain.Session{ID: id}
+	err := s.db.QueryRowContext(ctx,
+		`SELECT data, created_at, last_active_at, expires_at FROM sessions WHERE id = $1 AND expires_at > now()`, id,
+	).Scan(&sess.Data, &sess.CreatedAt, &sess.LastActiveAt, &sess.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ports.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return &sess, nil
+}
+
+// Save inserts or replaces the session
+func (s *Store) Save(ctx context.Context, sess *domain.Session) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO sessions (id, data, created_at, last_active_at, expires_at)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (id) DO UPDATE
+		 SET data = EXCLUDED.data, last_active_at = EXCLUDED.last_active_at, expires_at = EXCLUDED.expires_at`,
+		sess.ID, sess.Data, sess.CreatedAt, sess.LastActiveAt, sess.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the session
+func (s *Store) Delete(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// Run deletes expired sessions every interval until ctx is done
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		res, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at <= now()`)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("failed to delete expired sessions", "error", err)
+			}
+			continue
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			s.logger.Debug("expired sessions deleted", "count", n)
+		}
+	}
+}
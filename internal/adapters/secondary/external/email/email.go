@@ -0,0 +1,82 @@
+package email
+
+import (
+	"fmt"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/core/domain"
+	"go-chi-boilerplate/internal/core/ports"
+	"log/slog"
+	"net/mail"
+	"sort"
+	"sync"
+)
+
+// Provider builds a Mailer from the mail configs
+type Provider func(cfg *config.MailConfigs, logger *slog.Logger) (ports.Mailer, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{
+		"smtp": func(cfg *config.MailConfigs, logger *slog.Logger) (ports.Mailer, error) {
+			return NewSMTP(cfg, logger), nil
+		},
+		"file": func(cfg *config.MailConfigs, logger *slog.Logger) (ports.Mailer, error) {
+			return NewSink(cfg.SinkDir, cfg.From, logger)
+		},
+		"log": func(cfg *config.MailConfigs, logger *slog.Logger) (ports.Mailer, error) {
+			return NewSink("", cfg.From, logger)
+		},
+	}
+)
+
+// Register makes a mail provider available under name, replacing any existing one
+func Register(name string, p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = p
+}
+
+// New creates the Mailer selected by MAIL_PROVIDER
+func New(cfg *config.MailConfigs, logger *slog.Logger) (ports.Mailer, error) {
+	providersMu.RLock()
+	p, ok := providers[cfg.Provider]
+	providersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown mail provider %q (available: %v)", cfg.Provider, providerNames())
+	}
+
+	logger.Info("mail provider configured", "provider", cfg.Provider)
+	return p(cfg, logger)
+}
+
+func providerNames() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withDefaultFrom returns e with From set to from when it is empty
+func withDefaultFrom(e *domain.Email, from string) *domain.Email {
+	if e.From != "" {
+		return e
+	}
+	c := *e
+	c.From = from
+	return &c
+}
+
+// envelopeAddress extracts the bare address from a header value such as "Name <user@example.com>"
+func envelopeAddress(addr string) (string, error) {
+	a, err := mail.ParseAddress(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid email address %q: %w", addr, err)
+	}
+	return a.Address, nil
+}
@@ -0,0 +1,186 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/core/domain"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// buildMessage renders an Email as an RFC 5322 message with a MIME body:
+// text and HTML become multipart/alternative, attachments wrap it in multipart/mixed.
+func buildMessage(e *domain.Email) ([]byte, error) {
+	if e.Text == "" && e.HTML == "" {
+		return nil, errors.New("email has neither a text nor an HTML body")
+	}
+
+	var buf bytes.Buffer
+
+	writeHeader(&buf, "From", e.From)
+	writeHeader(&buf, "To", strings.Join(e.To, ", "))
+	if len(e.Cc) > 0 {
+		writeHeader(&buf, "Cc", strings.Join(e.Cc, ", "))
+	}
+	if e.ReplyTo != "" {
+		writeHeader(&buf, "Reply-To", e.ReplyTo)
+	}
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", e.Subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID(e.From))
+	writeHeader(&buf, "MIME-Version", "1.0")
+	for k, v := range e.Headers {
+		writeHeader(&buf, k, v)
+	}
+
+	header, writeBody := bodyPart(e)
+
+	if len(e.Attachments) == 0 {
+		for k := range header {
+			writeHeader(&buf, k, header.Get(k))
+		}
+		buf.WriteString("\r\n")
+		if err := writeBody(&buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	buf.WriteString("\r\n")
+
+	body, err := mixed.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBody(body); err != nil {
+		return nil, err
+	}
+
+	for _, a := range e.Attachments {
+		if err := writeAttachment(mixed, a); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// bodyPart returns the headers and writer of the text/HTML body, which is
+// multipart/alternative when both variants are present
+func bodyPart(e *domain.Email) (textproto.MIMEHeader, func(io.Writer) error) {
+	if e.Text != "" && e.HTML != "" {
+		boundary := multipart.NewWriter(io.Discard).Boundary()
+		header := textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + boundary}}
+
+		return header, func(w io.Writer) error {
+			alt := multipart.NewWriter(w)
+			if err := alt.SetBoundary(boundary); err != nil {
+				return err
+			}
+			if err := writeTextPart(alt, "text/plain", e.Text); err != nil {
+				return err
+			}
+			if err := writeTextPart(alt, "text/html", e.HTML); err != nil {
+				return err
+			}
+			return alt.Close()
+		}
+	}
+
+	contentType, content := "text/plain", e.Text
+	if e.HTML != "" {
+		contentType, content = "text/html", e.HTML
+	}
+
+	header := textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	}
+	return header, func(w io.Writer) error {
+		return writeQuotedPrintable(w, content)
+	}
+}
+
+func writeTextPart(mw *multipart.Writer, contentType, content string) error {
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	return writeQuotedPrintable(part, content)
+}
+
+func writeAttachment(mw *multipart.Writer, a domain.Attachment) error {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := textproto.MIMEHeader{
+		"Content-Type":              {fmt.Sprintf("%s; name=%q", contentType, a.Filename)},
+		"Content-Transfer-Encoding": {"base64"},
+	}
+	if a.ContentID != "" {
+		header.Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", a.Filename))
+		header.Set("Content-ID", "<"+a.ContentID+">")
+	} else {
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", a.Filename))
+	}
+
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	// RFC 2045 limits encoded lines to 76 characters
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(part, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = io.WriteString(part, encoded+"\r\n")
+	return err
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	// Strip CR/LF so header values cannot inject additional headers
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+}
+
+func messageID(from string) string {
+	domainPart := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domainPart = strings.Trim(from[at+1:], "> ")
+	}
+
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(id), domainPart)
+}
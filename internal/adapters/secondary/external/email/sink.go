@@ -0,0 +1,67 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"go-chi-boilerplate/internal/core/domain"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// SinkMailer is a development Mailer that writes messages to .eml files instead of
+// delivering them, or only logs them when no directory is configured
+type SinkMailer struct {
+	dir    string
+	from   string
+	logger *slog.Logger
+}
+
+// NewSink creates a SinkMailer writing into dir; an empty dir only logs messages
+func NewSink(dir, from string, logger *slog.Logger) (*SinkMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create mail sink directory: %w", err)
+		}
+	}
+
+	return &SinkMailer{
+		dir:    dir,
+		from:   from,
+		logger: logger,
+	}, nil
+}
+
+// Send renders the message and stores or logs it
+func (s *SinkMailer) Send(ctx context.Context, e *domain.Email) error {
+	e = withDefaultFrom(e, s.from)
+
+	msg, err := buildMessage(e)
+	if err != nil {
+		return err
+	}
+
+	if s.dir == "" {
+		s.logger.InfoContext(ctx, "email captured by log sink",
+			"from", e.From, "to", e.To, "subject", e.Subject,
+			"attachments", len(e.Attachments), "size_bytes", len(msg),
+		)
+		return nil
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(),
+		strings.Trim(unsafeFilenameChars.ReplaceAllString(strings.ToLower(e.Subject), "-"), "-"))
+	path := filepath.Join(s.dir, name)
+
+	if err := os.WriteFile(path, msg, 0o644); err != nil {
+		return fmt.Errorf("failed to write email to sink: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "email written to file sink", "to", e.To, "subject", e.Subject, "path", path)
+	return nil
+}
@@ -0,0 +1,202 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/core/domain"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"sync"
+	"time"
+)
+
+// SMTPMailer delivers email over SMTP, reusing one connection between sends
+// until it has been idle for longer than the configured idle timeout
+type SMTPMailer struct {
+	cfg    *config.MailConfigs
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// NewSMTP creates an SMTPMailer; the connection is established on first send
+func NewSMTP(cfg *config.MailConfigs, logger *slog.Logger) *SMTPMailer {
+	return &SMTPMailer{
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Send delivers the email, retrying once on a fresh connection if a reused one has gone stale
+func (m *SMTPMailer) Send(ctx context.Context, e *domain.Email) error {
+	e = withDefaultFrom(e, m.cfg.From)
+
+	from, err := envelopeAddress(e.From)
+	if err != nil {
+		return err
+	}
+
+	rcpts := make([]string, 0, len(e.Recipients()))
+	for _, r := range e.Recipients() {
+		addr, err := envelopeAddress(r)
+		if err != nil {
+			return err
+		}
+		rcpts = append(rcpts, addr)
+	}
+	if len(rcpts) == 0 {
+		return errors.New("email has no recipients")
+	}
+
+	msg, err := buildMessage(e)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reused := m.client != nil && time.Since(m.lastUsed) < m.cfg.SMTPIdle
+	if err := m.sendLocked(ctx, from, rcpts, msg); err != nil {
+		m.closeLocked()
+		if !reused {
+			return err
+		}
+
+		m.logger.DebugContext(ctx, "retrying email on a fresh SMTP connection", "error", err)
+		if err := m.sendLocked(ctx, from, rcpts, msg); err != nil {
+			m.closeLocked()
+			return err
+		}
+	}
+
+	m.lastUsed = time.Now()
+	m.logger.InfoContext(ctx, "email sent", "to", e.To, "subject", e.Subject)
+	return nil
+}
+
+// Close ends the SMTP session if one is open
+func (m *SMTPMailer) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client != nil {
+		_ = m.refreshDeadlineLocked()
+		if err := m.client.Quit(); err != nil {
+			m.logger.Warn("failed to quit SMTP session", "error", err)
+		}
+		m.client = nil
+		m.conn = nil
+	}
+}
+
+func (m *SMTPMailer) sendLocked(ctx context.Context, from string, rcpts []string, msg []byte) error {
+	c, err := m.clientLocked(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(m.cfg.SMTPTimeout)
+	}
+	if err := m.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("smtp MAIL FROM: %w", err)
+	}
+	for _, r := range rcpts {
+		if err := c.Rcpt(r); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s: %w", r, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("smtp write body: %w", err)
+	}
+	return w.Close()
+}
+
+// clientLocked returns the open client, resetting its transaction state, or dials a new one
+func (m *SMTPMailer) clientLocked(ctx context.Context) (*smtp.Client, error) {
+	if m.client != nil {
+		// The deadline of the previous send has passed on an idle connection
+		if time.Since(m.lastUsed) < m.cfg.SMTPIdle && m.refreshDeadlineLocked() == nil && m.client.Reset() == nil {
+			return m.client, nil
+		}
+		m.closeLocked()
+	}
+
+	host := m.cfg.SMTPHost
+	addr := net.JoinHostPort(host, m.cfg.SMTPPort)
+	tlsCfg := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+
+	dialer := &net.Dialer{Timeout: m.cfg.SMTPTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	// Port 465 speaks implicit TLS (SMTPS) rather than upgrading with STARTTLS
+	implicitTLS := m.cfg.SMTPPort == "465"
+	if implicitTLS {
+		conn = tls.Client(conn, tlsCfg)
+	}
+	_ = conn.SetDeadline(time.Now().Add(m.cfg.SMTPTimeout))
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+
+	if !implicitTLS && m.cfg.SMTPStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			c.Close()
+			return nil, errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := c.StartTLS(tlsCfg); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("smtp STARTTLS: %w", err)
+		}
+	}
+
+	if m.cfg.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", m.cfg.SMTPUsername, m.cfg.SMTPPassword, host)
+		if err := c.Auth(auth); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("smtp AUTH: %w", err)
+		}
+	}
+
+	m.logger.Debug("SMTP connection established", "addr", addr)
+	m.conn = conn
+	m.client = c
+	return c, nil
+}
+
+// refreshDeadlineLocked gives the open connection SMTP_TIMEOUT for the next command
+func (m *SMTPMailer) refreshDeadlineLocked() error {
+	return m.conn.SetDeadline(time.Now().Add(m.cfg.SMTPTimeout))
+}
+
+func (m *SMTPMailer) closeLocked() {
+	if m.client != nil {
+		m.client.Close()
+	}
+	m.client = nil
+	m.conn = nil
+}
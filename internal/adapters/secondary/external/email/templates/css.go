@@ -0,0 +1,200 @@
+package templates
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var cssComments = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// cssRule is one selector of a style rule; rules with selector lists are split
+type cssRule struct {
+	tag         string
+	id          string
+	classes     []string
+	decls       string
+	specificity int
+	order       int
+}
+
+// inlineCSS copies the rules of <style> blocks into style attributes, since many
+// mail clients ignore stylesheets. Only simple selectors (tag, .class, #id and
+// compounds like a.button) are inlined; the <style> blocks are kept for clients
+// that do support them, e.g. for media queries.
+func inlineCSS(document string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return "", err
+	}
+
+	var css strings.Builder
+	walk(doc, func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "style" && n.FirstChild != nil {
+			css.WriteString(n.FirstChild.Data)
+		}
+	})
+
+	rules := parseCSS(css.String())
+	if len(rules) == 0 {
+		return document, nil
+	}
+
+	walk(doc, func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+
+		var decls []string
+		for _, rule := range rules {
+			if rule.matches(n) {
+				decls = append(decls, rule.decls)
+			}
+		}
+		if len(decls) == 0 {
+			return
+		}
+
+		// Existing inline styles keep the highest precedence
+		if existing := attr(n, "style"); existing != "" {
+			decls = append(decls, strings.TrimSuffix(existing, ";"))
+		}
+		setAttr(n, "style", strings.Join(decls, "; "))
+	})
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// parseCSS returns the inlinable rules ordered by specificity, then source order
+func parseCSS(css string) []cssRule {
+	css = cssComments.ReplaceAllString(css, "")
+
+	var rules []cssRule
+	var selectors string
+	depth, start := 0, 0
+
+	for i, ch := range css {
+		switch ch {
+		case '{':
+			if depth == 0 {
+				selectors = strings.TrimSpace(css[start:i])
+				start = i + 1
+			}
+			depth++
+		case '}':
+			depth--
+			if depth > 0 {
+				continue
+			}
+
+			// At-rules such as @media are left to the <style> block
+			decls := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(css[start:i]), ";"))
+			if !strings.HasPrefix(selectors, "@") && decls != "" {
+				for _, sel := range strings.Split(selectors, ",") {
+					if rule, ok := parseSelector(strings.TrimSpace(sel)); ok {
+						rule.decls = decls
+						rule.order = len(rules)
+						rules = append(rules, rule)
+					}
+				}
+			}
+			start = i + 1
+		}
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].specificity != rules[j].specificity {
+			return rules[i].specificity < rules[j].specificity
+		}
+		return rules[i].order < rules[j].order
+	})
+	return rules
+}
+
+// parseSelector accepts compound selectors without combinators, pseudo-classes or attributes
+func parseSelector(sel string) (cssRule, bool) {
+	if sel == "" || strings.ContainsAny(sel, " >+~:[*") {
+		return cssRule{}, false
+	}
+
+	var rule cssRule
+	for sel != "" {
+		next := strings.IndexAny(sel[1:], ".#") + 1
+		if next == 0 {
+			next = len(sel)
+		}
+		part := sel[:next]
+		sel = sel[next:]
+
+		switch part[0] {
+		case '#':
+			rule.id = part[1:]
+			rule.specificity += 100
+		case '.':
+			rule.classes = append(rule.classes, part[1:])
+			rule.specificity += 10
+		default:
+			rule.tag = strings.ToLower(part)
+			rule.specificity++
+		}
+	}
+	return rule, true
+}
+
+func (r cssRule) matches(n *html.Node) bool {
+	if r.tag != "" && r.tag != n.Data {
+		return false
+	}
+	if r.id != "" && r.id != attr(n, "id") {
+		return false
+	}
+	if len(r.classes) > 0 {
+		classes := strings.Fields(attr(n, "class"))
+		for _, want := range r.classes {
+			found := false
+			for _, c := range classes {
+				if c == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func walk(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *html.Node, key, val string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
@@ -0,0 +1,235 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/core/domain"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed files
+var embedded embed.FS
+
+// ErrTemplateNotFound is returned when no locale variant of a template exists
+var ErrTemplateNotFound = errors.New("email template not found")
+
+// Rendered is the output of rendering a template for one locale
+type Rendered struct {
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// Email builds a message addressed to the given recipients from the rendered content
+func (r *Rendered) Email(to ...string) *domain.Email {
+	return &domain.Email{
+		To:      to,
+		Subject: r.Subject,
+		HTML:    r.HTML,
+		Text:    r.Text,
+	}
+}
+
+// layoutData is what the layouts see; templates receive Data as their dot
+type layoutData struct {
+	Locale  string
+	Subject string
+	Service string
+	Data    any
+}
+
+// Renderer renders email templates laid out as <name>/<locale>.{subject,html,txt}.tmpl
+// with shared layouts in layouts/base.{html,txt}.tmpl
+type Renderer struct {
+	fsys          fs.FS
+	service       string
+	defaultLocale string
+}
+
+// New creates a Renderer over the embedded templates
+func New(service, defaultLocale string) (*Renderer, error) {
+	sub, err := fs.Sub(embedded, "files")
+	if err != nil {
+		return nil, err
+	}
+	return NewFromFS(sub, service, defaultLocale), nil
+}
+
+// NewFromFS creates a Renderer over templates in fsys, e.g. os.DirFS for overrides
+func NewFromFS(fsys fs.FS, service, defaultLocale string) *Renderer {
+	return &Renderer{
+		fsys:          fsys,
+		service:       service,
+		defaultLocale: strings.ToLower(defaultLocale),
+	}
+}
+
+// Render renders template name for the closest available locale, falling back from
+// "fr-ca" to "fr" and then to the default locale
+func (r *Renderer) Render(name, locale string, data any) (*Rendered, error) {
+	loc, err := r.resolveLocale(name, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := r.renderSubject(name, loc, data)
+	if err != nil {
+		return nil, err
+	}
+
+	ld := layoutData{Locale: loc, Subject: subject, Service: r.service, Data: data}
+
+	html, err := r.renderHTML(name, loc, ld)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := r.renderText(name, loc, ld)
+	if err != nil {
+		return nil, err
+	}
+
+	if html == "" && text == "" {
+		return nil, fmt.Errorf("email template %q has neither an HTML nor a text body", name)
+	}
+
+	return &Rendered{Locale: loc, Subject: subject, HTML: html, Text: text}, nil
+}
+
+// Templates lists the available template names
+func (r *Renderer) Templates() ([]string, error) {
+	entries, err := fs.ReadDir(r.fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != "layouts" {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// SampleData returns the sample.json data shipped with a template, used for previews
+func (r *Renderer) SampleData(name string) (map[string]any, error) {
+	data := map[string]any{}
+
+	raw, err := fs.ReadFile(r.fsys, path.Join(name, "sample.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("invalid sample data for %q: %w", name, err)
+	}
+	return data, nil
+}
+
+func (r *Renderer) resolveLocale(name, locale string) (string, error) {
+	for _, loc := range localeCandidates(locale, r.defaultLocale) {
+		if r.exists(name, loc, "subject") {
+			return loc, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s (locale %q)", ErrTemplateNotFound, name, locale)
+}
+
+// localeCandidates turns "fr_CA" into ["fr-ca", "fr", <default>]
+func localeCandidates(locale, defaultLocale string) []string {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+
+	var out []string
+	for locale != "" {
+		out = append(out, locale)
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return append(out, defaultLocale)
+}
+
+func (r *Renderer) exists(name, locale, kind string) bool {
+	_, err := fs.Stat(r.fsys, r.file(name, locale, kind))
+	return err == nil
+}
+
+func (r *Renderer) file(name, locale, kind string) string {
+	return path.Join(name, locale+"."+kind+".tmpl")
+}
+
+func (r *Renderer) renderSubject(name, locale string, data any) (string, error) {
+	t, err := texttemplate.New(path.Base(r.file(name, locale, "subject"))).
+		Funcs(texttemplate.FuncMap(funcs)).
+		ParseFS(r.fsys, r.file(name, locale, "subject"))
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render subject of %q: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func (r *Renderer) renderHTML(name, locale string, ld layoutData) (string, error) {
+	if !r.exists(name, locale, "html") {
+		return "", nil
+	}
+
+	t, err := htmltemplate.New("base.html.tmpl").
+		Funcs(htmltemplate.FuncMap(funcs)).
+		ParseFS(r.fsys, "layouts/base.html.tmpl", r.file(name, locale, "html"))
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, ld); err != nil {
+		return "", fmt.Errorf("failed to render HTML body of %q: %w", name, err)
+	}
+	return inlineCSS(buf.String())
+}
+
+func (r *Renderer) renderText(name, locale string, ld layoutData) (string, error) {
+	if !r.exists(name, locale, "txt") {
+		return "", nil
+	}
+
+	t, err := texttemplate.New("base.txt.tmpl").
+		Funcs(texttemplate.FuncMap(funcs)).
+		ParseFS(r.fsys, "layouts/base.txt.tmpl", r.file(name, locale, "txt"))
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, ld); err != nil {
+		return "", fmt.Errorf("failed to render text body of %q: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()) + "\n", nil
+}
+
+var funcs = map[string]any{
+	"year":  func() int { return time.Now().Year() },
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/core/domain"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often counters that no longer limit anything are removed
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket is back at capacity and can be forgotten
+	full time.Time
+}
+
+type window struct {
+	start           time.Time
+	length          time.Duration
+	count, previous int
+}
+
+// Memory keeps counters in process memory: limits apply per replica and reset on
+// restart. It implements ports.RateLimiter.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	windows   map[string]*window
+	lastSweep time.Time
+}
+
+// NewMemory creates an empty Memory
+func NewMemory() *Memory {
+	return &Memory{
+		buckets:   make(map[string]*bucket),
+		windows:   make(map[string]*window),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow counts one request against limit for key
+func (m *Memory) Allow(_ context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error) {
+	return m.take(key, limit, true)
+}
+
+// Peek reports whether a request for key would be allowed without counting it
+func (m *Memory) Peek(_ context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error) {
+	return m.take(key, limit, false)
+}
+
+func (m *Memory) take(key string, limit domain.RateLimit, count bool) (domain.RateLimitResult, error) {
+	if err := checkLimit(limit); err != nil {
+		return domain.RateLimitResult{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+
+	if limit.Algorithm == domain.TokenBucket {
+		return m.takeToken(key, limit, now, count), nil
+	}
+	return m.countWindow(key, limit, now, count), nil
+}
+
+func (m *Memory) takeToken(key string, limit domain.RateLimit, now time.Time, count bool) domain.RateLimitResult {
+	rate := refillRate(limit)
+	capacity := float64(limit.Capacity())
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		m.buckets[key] = b
+	}
+	b.tokens = min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed && count {
+		b.tokens--
+	}
+	res := tokenBucketResult(limit, b.tokens, allowed)
+	b.full = now.Add(res.Reset)
+	return res
+}
+
+func (m *Memory) countWindow(key string, limit domain.RateLimit, now time.Time, count bool) domain.RateLimitResult {
+	start := now.Truncate(limit.Window)
+
+	w, ok := m.windows[key]
+	switch {
+	case !ok:
+		w = &window{start: start, length: limit.Window}
+		m.windows[key] = w
+	case w.length != limit.Window:
+		w.start, w.length, w.previous, w.count = start, limit.Window, 0, 0
+	case w.start.Equal(start):
+	case w.start.Add(limit.Window).Equal(start):
+		w.start, w.previous, w.count = start, w.count, 0
+	default:
+		w.start, w.previous, w.count = start, 0, 0
+	}
+
+	elapsed := now.Sub(start)
+	used := float64(w.previous)*(1-elapsed.Seconds()/limit.Window.Seconds()) + float64(w.count)
+	allowed := used+1 <= float64(limit.Limit)
+	if allowed && count {
+		w.count++
+	}
+	return slidingWindowResult(limit, w.previous, w.count, elapsed, allowed)
+}
+
+// sweep removes full buckets and windows that ended long enough ago to no longer count
+func (m *Memory) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if !now.Before(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+	for key, w := range m.windows {
+		if !now.Before(w.start.Add(2 * w.length)) {
+			delete(m.windows, key)
+		}
+	}
+	m.lastSweep = now
+}
@@ -0,0 +1,83 @@
+// Package ratelimit implements ports.RateLimiter with a token bucket and a sliding
+// window counter, in process memory or in Redis. The stores only keep the counters
+// and decide atomically; the results are derived from the counters here so both
+// stores report the same headers.
+package ratelimit
+
+import (
+	"fmt"
+	"go-chi-boilerplate/internal/core/domain"
+	"math"
+	"time"
+)
+
+// checkLimit rejects quotas the algorithms can't count
+func checkLimit(limit domain.RateLimit) error {
+	if limit.Limit <= 0 || limit.Window < time.Millisecond {
+		return fmt.Errorf("rate limit must allow at least one request per millisecond or longer, got %d per %s", limit.Limit, limit.Window)
+	}
+	if limit.Algorithm != domain.TokenBucket && limit.Algorithm != domain.SlidingWindow {
+		return fmt.Errorf("unknown rate limit algorithm %q", limit.Algorithm)
+	}
+	return nil
+}
+
+// refillRate is the number of tokens a bucket regains per second
+func refillRate(limit domain.RateLimit) float64 {
+	return float64(limit.Limit) / limit.Window.Seconds()
+}
+
+// tokenBucketResult describes a bucket left with tokens after a request
+func tokenBucketResult(limit domain.RateLimit, tokens float64, allowed bool) domain.RateLimitResult {
+	rate := refillRate(limit)
+	res := domain.RateLimitResult{
+		Allowed:   allowed,
+		Remaining: int(tokens),
+		Reset:     seconds((float64(limit.Capacity()) - tokens) / rate),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return res
+}
+
+// slidingWindowResult describes a sliding window after a request, elapsed into the
+// current window with count requests in it and previous in the window before
+func slidingWindowResult(limit domain.RateLimit, previous, count int, elapsed time.Duration, allowed bool) domain.RateLimitResult {
+	window := limit.Window.Seconds()
+	progress := elapsed.Seconds() / window
+	used := float64(previous)*(1-progress) + float64(count)
+
+	res := domain.RateLimitResult{
+		Allowed:   allowed,
+		Remaining: max(limit.Limit-int(math.Ceil(used)), 0),
+	}
+	switch {
+	case count > 0:
+		// The requests of this window stop counting at the end of the next one
+		res.Reset = seconds(2*window - elapsed.Seconds())
+	case previous > 0:
+		res.Reset = seconds(window - elapsed.Seconds())
+	}
+	if allowed {
+		return res
+	}
+
+	// Wait until enough of the previous window has slid out, or if this window alone
+	// is over the limit, until enough of it has in the next one
+	free := float64(limit.Limit - 1 - count)
+	if free >= 0 && previous > 0 {
+		res.RetryAfter = seconds((1-free/float64(previous))*window - elapsed.Seconds())
+	} else {
+		res.RetryAfter = seconds(window - elapsed.Seconds() + (1-float64(limit.Limit-1)/float64(count))*window)
+	}
+	return res
+}
+
+// seconds converts s to a Duration, rounded up to the millisecond the stores count in
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(s*1000)) * time.Millisecond
+}
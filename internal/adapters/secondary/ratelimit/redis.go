@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"go-chi-boilerplate/internal/core/domain"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces the counters of each algorithm in Redis
+const keyPrefix = "ratelimit:"
+
+// Both scripts read the clock of the Redis server, so replicas with skewed clocks
+// still share one view of the windows. Times are in milliseconds.
+
+// tokenBucketScript takes a token from the bucket KEYS[1] refilled at ARGV[1] tokens
+// per millisecond up to ARGV[2], or with ARGV[3] = 0 only checks for one, and returns
+// whether there was one and the tokens left
+var tokenBucketScript = goredis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - cost
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript counts a request in the window KEYS[1] of ARGV[1] milliseconds
+// unless that exceeds ARGV[2], or with ARGV[3] = 0 only checks whether it would, and
+// returns whether it could, the counts of the previous and current window and the
+// time elapsed in the current one
+var slidingWindowScript = goredis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local length = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local start = now - (now % length)
+
+local state = redis.call('HMGET', KEYS[1], 'start', 'count', 'previous')
+local last = tonumber(state[1])
+local count, previous = 0, 0
+if last == start then
+	count = tonumber(state[2]) or 0
+	previous = tonumber(state[3]) or 0
+elseif last == start - length then
+	previous = tonumber(state[2]) or 0
+end
+
+local elapsed = now - start
+local allowed = 0
+if previous * (1 - elapsed / length) + count + 1 <= limit then
+	count = count + cost
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'start', start, 'count', count, 'previous', previous)
+redis.call('PEXPIRE', KEYS[1], 2 * length - elapsed)
+return {allowed, previous, count, elapsed}
+`)
+
+// Redis keeps counters in Redis so a limit applies across all replicas. It
+// implements ports.RateLimiter.
+type Redis struct {
+	client *goredis.Client
+}
+
+// NewRedis creates a Redis on client
+func NewRedis(client *goredis.Client) *Redis {
+	return &Redis{client: client}
+}
+
+// Allow counts one request against limit for key
+func (s *Redis) Allow(ctx context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error) {
+	return s.take(ctx, key, limit, 1)
+}
+
+// Peek reports whether a request for key would be allowed without counting it
+func (s *Redis) Peek(ctx context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error) {
+	return s.take(ctx, key, limit, 0)
+}
+
+func (s *Redis) take(ctx context.Context, key string, limit domain.RateLimit, cost int) (domain.RateLimitResult, error) {
+	if err := checkLimit(limit); err != nil {
+		return domain.RateLimitResult{}, err
+	}
+
+	if limit.Algorithm == domain.TokenBucket {
+		perMillisecond := refillRate(limit) / 1000
+		reply, err := tokenBucketScript.Run(ctx, s.client, []string{keyPrefix + "tb:" + key},
+			strconv.FormatFloat(perMillisecond, 'g', -1, 64), limit.Capacity(), cost,
+		).Slice()
+		if err != nil {
+			return domain.RateLimitResult{}, fmt.Errorf("failed to take rate limit token: %w", err)
+		}
+		if len(reply) != 2 {
+			return domain.RateLimitResult{}, fmt.Errorf("unexpected token bucket reply %v", reply)
+		}
+		allowed, _ := reply[0].(int64)
+		tokens, err := strconv.ParseFloat(fmt.Sprint(reply[1]), 64)
+		if err != nil {
+			return domain.RateLimitResult{}, fmt.Errorf("unexpected token bucket reply %v: %w", reply, err)
+		}
+		return tokenBucketResult(limit, tokens, allowed == 1), nil
+	}
+
+	reply, err := slidingWindowScript.Run(ctx, s.client, []string{keyPrefix + "sw:" + key},
+		limit.Window.Milliseconds(), limit.Limit, cost,
+	).Int64Slice()
+	if err != nil {
+		return domain.RateLimitResult{}, fmt.Errorf("failed to count rate limited request: %w", err)
+	}
+	if len(reply) != 4 {
+		return domain.RateLimitResult{}, fmt.Errorf("unexpected sliding window reply %v", reply)
+	}
+	return slidingWindowResult(limit, int(reply[1]), int(reply[2]), time.Duration(reply[3])*time.Millisecond, reply[0] == 1), nil
+}
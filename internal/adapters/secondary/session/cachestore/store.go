@@ -0,0 +1,72 @@
+// Package cachestore keeps sessions in a ports.Cache, e.g. Redis, so every replica
+// sees the same sessions.
+package cachestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-chi-boilerplate/internal/core/domain"
+	"go-chi-boilerplate/internal/core/ports"
+	"time"
+)
+
+// keyPrefix namespaces session keys in the cache
+const keyPrefix = "session:"
+
+// Store implements ports.SessionStore on a cache; sessions expire with their cache keys
+type Store struct {
+	cache ports.Cache
+}
+
+// New creates a Store on cache. Pass the Redis cache itself rather than a tiered one:
+// a local tier would keep serving sessions deleted by other replicas.
+func New(cache ports.Cache) *Store {
+	return &Store{cache: cache}
+}
+
+// Get returns the session stored under id
+func (s *Store) Get(ctx context.Context, id string) (*domain.Session, error) {
+	data, err := s.cache.Get(ctx, keyPrefix+id)
+	if errors.Is(err, ports.ErrCacheMiss) {
+		return nil, ports.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	var sess domain.Session
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	if !time.Now().Before(sess.ExpiresAt) {
+		return nil, ports.ErrSessionNotFound
+	}
+	return &sess, nil
+}
+
+// Save stores sess until it expires
+func (s *Store) Save(ctx context.Context, sess *domain.Session) error {
+	ttl := time.Until(sess.ExpiresAt)
+	if ttl <= 0 {
+		return s.Delete(ctx, sess.ID)
+	}
+
+	data, err := json.Marshal(sess)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := s.cache.Set(ctx, keyPrefix+sess.ID, data, ttl); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the session
+func (s *Store) Delete(ctx context.Context, id string) error {
+	if err := s.cache.Delete(ctx, keyPrefix+id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Package memory keeps sessions in process memory. Sessions are lost on restart and
+// not shared between replicas, so it suits development and single-instance deployments.
+package memory
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/core/domain"
+	"go-chi-boilerplate/internal/core/ports"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired sessions are removed
+const sweepInterval = time.Minute
+
+// Store implements ports.SessionStore
+type Store struct {
+	mu        sync.Mutex
+	sessions  map[string]domain.Session
+	lastSweep time.Time
+}
+
+// New creates an empty Store
+func New() *Store {
+	return &Store{sessions: make(map[string]domain.Session), lastSweep: time.Now()}
+}
+
+// Get returns the session stored under id
+func (s *Store) Get(_ context.Context, id string) (*domain.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok || !time.Now().Before(sess.ExpiresAt) {
+		return nil, ports.ErrSessionNotFound
+	}
+	return &sess, nil
+}
+
+// Save stores a copy of sess and removes expired sessions once per sweepInterval
+func (s *Store) Save(_ context.Context, sess *domain.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for id, other := range s.sessions {
+			if !now.Before(other.ExpiresAt) {
+				delete(s.sessions, id)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	c := *sess
+	c.Data = append([]byte(nil), sess.Data...)
+	s.sessions[sess.ID] = c
+	return nil
+}
+
+// Delete removes the session
+func (s *Store) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
@@ -0,0 +1,276 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/apikey"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/jwt"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/oidc"
+	"go-chi-boilerplate/internal/adapters/secondary/auth/policy"
+	"go-chi-boilerplate/internal/adapters/secondary/cache/local"
+	"go-chi-boilerplate/internal/adapters/secondary/cache/redis"
+	"go-chi-boilerplate/internal/adapters/secondary/cache/tiered"
+	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql"
+	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql/apikeys"
+	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql/sessions"
+	"go-chi-boilerplate/internal/adapters/secondary/external/email"
+	"go-chi-boilerplate/internal/adapters/secondary/external/email/templates"
+	"go-chi-boilerplate/internal/adapters/secondary/ratelimit"
+	"go-chi-boilerplate/internal/adapters/secondary/session/cachestore"
+	"go-chi-boilerplate/internal/adapters/secondary/session/memory"
+	"go-chi-boilerplate/internal/config"
+	"go-chi-boilerplate/internal/core/health"
+	"go-chi-boilerplate/internal/core/ports"
+	"go-chi-boilerplate/internal/meta"
+	"log/slog"
+	"time"
+)
+
+// Container builds the adapters shared by the CLI commands on first use and
+// closes them in reverse order on Close
+type Container struct {
+	Config *config.AppConfigs
+	Logger *slog.Logger
+
+	db       *postgresql.PostgresDB
+	redis    *redis.RedisCache
+	cache    ports.Cache
+	mailer   ports.Mailer
+	renderer *templates.Renderer
+	verifier ports.TokenVerifier
+	apiKeys  *apikey.Manager
+	oidc     *oidc.Client
+	sessions ports.SessionStore
+	limiter  ports.RateLimiter
+	policy   ports.PolicyEngine
+	health   *health.Checker
+	closers  []func()
+}
+
+// New creates a Container for the given configs
+func New(cfg *config.AppConfigs) *Container {
+	return &Container{
+		Config: cfg,
+		Logger: meta.NewLogger(cfg.Server.LogLevel),
+		health: health.NewChecker(1 * time.Second),
+	}
+}
+
+// DB connects to PostgreSQL and registers it with the readiness checks
+func (c *Container) DB() (*postgresql.PostgresDB, error) {
+	if c.db != nil {
+		return c.db, nil
+	}
+
+	db, err := postgresql.New(c.Config.Database, c.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	c.db = db
+	c.health.Add("database", db.Ping)
+	c.onClose(db.Close)
+	return db, nil
+}
+
+// Redis connects to Redis; it returns nil when REDIS_ADDR is not set
+func (c *Container) Redis() (*redis.RedisCache, error) {
+	if c.redis != nil || !c.Config.Redis.Enabled() {
+		return c.redis, nil
+	}
+
+	rc, err := redis.New(c.Config.Redis, c.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	c.redis = rc
+	c.health.Add("redis", rc.Ping)
+	c.onClose(rc.Close)
+	return rc, nil
+}
+
+// Cache puts the optional in-process tier in front of Redis; it returns nil when
+// Redis is not configured. Remote invalidations are applied until ctx is done.
+func (c *Container) Cache(ctx context.Context) (ports.Cache, error) {
+	if c.cache != nil {
+		return c.cache, nil
+	}
+
+	rc, err := c.Redis()
+	if err != nil || rc == nil {
+		return nil, err
+	}
+
+	if !c.Config.Cache.LocalEnabled {
+		c.cache = tiered.New(ctx, nil, rc, nil, c.Logger)
+		return c.cache, nil
+	}
+
+	lru := local.New(c.Config.Cache.LocalMaxEntries, c.Config.Cache.LocalTTL)
+	bus := redis.NewInvalidator(rc, c.Config.Cache.InvalidationChannel, c.Logger)
+	c.cache = tiered.New(ctx, lru, rc, bus, c.Logger)
+	return c.cache, nil
+}
+
+// Mailer creates the Mailer selected by MAIL_PROVIDER
+func (c *Container) Mailer() (ports.Mailer, error) {
+	if c.mailer != nil {
+		return c.mailer, nil
+	}
+
+	mailer, err := email.New(c.Config.Mail, c.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mailer = mailer
+	if closer, ok := mailer.(interface{ Close() }); ok {
+		c.onClose(closer.Close)
+	}
+	return mailer, nil
+}
+
+// EmailRenderer loads the embedded email templates
+func (c *Container) EmailRenderer() (*templates.Renderer, error) {
+	if c.renderer != nil {
+		return c.renderer, nil
+	}
+
+	renderer, err := templates.New(c.Config.Server.ServiceName, c.Config.Mail.DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+
+	c.renderer = renderer
+	return renderer, nil
+}
+
+// TokenVerifier creates the JWT verifier; it returns nil when neither AUTH_JWKS_URL
+// nor AUTH_HMAC_SECRET is set
+func (c *Container) TokenVerifier() (ports.TokenVerifier, error) {
+	if c.verifier != nil || !c.Config.Auth.Enabled() {
+		return c.verifier, nil
+	}
+
+	verifier, err := jwt.New(c.Config.Auth, c.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	c.verifier = verifier
+	return verifier, nil
+}
+
+// APIKeys creates the API key manager on the api_keys table; it needs API_KEY_PEPPER
+func (c *Container) APIKeys() (*apikey.Manager, error) {
+	if c.apiKeys != nil {
+		return c.apiKeys, nil
+	}
+
+	db, err := c.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	manager, err := apikey.New(c.Config.APIKeys, apikeys.New(db.DB), c.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	c.apiKeys = manager
+	return manager, nil
+}
+
+// OIDC creates the OpenID Connect client; it returns nil when OIDC_ISSUER_URL is not set
+func (c *Container) OIDC() *oidc.Client {
+	if c.oidc == nil && c.Config.OIDC.Enabled() {
+		c.oidc = oidc.New(c.Config.OIDC, c.Logger)
+	}
+	return c.oidc
+}
+
+// SessionStore creates the server-side session store selected by SESSION_STORE; it
+// returns nil for cookie, which keeps sessions in the browser. Expired Postgres
+// sessions are deleted until ctx is done.
+func (c *Container) SessionStore(ctx context.Context) (ports.SessionStore, error) {
+	if c.sessions != nil {
+		return c.sessions, nil
+	}
+
+	switch c.Config.Session.Store {
+	case "memory":
+		c.sessions = memory.New()
+	case "postgres":
+		db, err := c.DB()
+		if err != nil {
+			return nil, err
+		}
+		store := sessions.New(db.DB, c.Logger)
+		go store.Run(ctx, 10*time.Minute)
+		c.sessions = store
+	case "redis":
+		rc, err := c.Redis()
+		if err != nil {
+			return nil, err
+		}
+		if rc == nil {
+			return nil, errors.New("SESSION_STORE=redis requires REDIS_ADDR")
+		}
+		c.sessions = cachestore.New(rc)
+	}
+	return c.sessions, nil
+}
+
+// RateLimiter creates the rate limit store selected by RATE_LIMIT_STORE
+func (c *Container) RateLimiter() (ports.RateLimiter, error) {
+	if c.limiter != nil {
+		return c.limiter, nil
+	}
+
+	if c.Config.RateLimit.Store != "redis" {
+		c.limiter = ratelimit.NewMemory()
+		return c.limiter, nil
+	}
+	rc, err := c.Redis()
+	if err != nil {
+		return nil, err
+	}
+	if rc == nil {
+		return nil, errors.New("RATE_LIMIT_STORE=redis requires REDIS_ADDR")
+	}
+	c.limiter = ratelimit.NewRedis(rc.Client)
+	return c.limiter, nil
+}
+
+// PolicyEngine creates the authorization engine selected by AUTH_POLICY_ENGINE
+func (c *Container) PolicyEngine() (ports.PolicyEngine, error) {
+	if c.policy != nil {
+		return c.policy, nil
+	}
+
+	engine, err := policy.New(c.Config.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	c.policy = engine
+	return engine, nil
+}
+
+// Health returns the readiness checks of the adapters created so far
+func (c *Container) Health() *health.Checker {
+	return c.health
+}
+
+// Close releases everything the container created
+func (c *Container) Close() {
+	for i := len(c.closers) - 1; i >= 0; i-- {
+		c.closers[i]()
+	}
+	c.closers = nil
+}
+
+func (c *Container) onClose(fn func()) {
+	c.closers = append(c.closers, fn)
+}
@@ -3,16 +3,42 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type ServerConfigs struct {
-	Port         string
-	ServiceName  string
-	LogLevel     string
-	OTLPEndpoint string
+	Port              string
+	ServiceName       string
+	Environment       string
+	LogLevel          string
+	OTLPEndpoint      string
+	OpenAPIValidation string
+}
+
+// SystemConfigs controls where the /system endpoints are served, which of them are
+// enabled and how they are protected. Liveness, readiness and health are always open.
+type SystemConfigs struct {
+	AdminPort    string
+	Endpoints    []string
+	AuthUsername string
+	AuthPassword string
+	AuthToken    string
+	AllowedCIDRs []string
+}
+
+type GRPCConfigs struct {
+	Enabled           bool
+	Port              string
+	Reflection        bool
+	HealthInterval    time.Duration
+	GatewayEnabled    bool
+	GatewayOpenAPIDir string
 }
 
 type DatabaseConfigs struct {
@@ -27,20 +53,173 @@ type DatabaseConfigs struct {
 	MaxLifetime  time.Duration
 }
 
+type RedisConfigs struct {
+	Addr     string
+	Password string
+	DB       int
+	PoolSize int
+}
+
+type CacheConfigs struct {
+	LocalEnabled        bool
+	LocalMaxEntries     int
+	LocalTTL            time.Duration
+	InvalidationChannel string
+}
+
+type MailConfigs struct {
+	Provider      string
+	From          string
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUsername  string
+	SMTPPassword  string
+	SMTPStartTLS  bool
+	SMTPTimeout   time.Duration
+	SMTPIdle      time.Duration
+	SinkDir       string
+	DefaultLocale string
+}
+
+type OutboxConfigs struct {
+	Enabled      bool
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+type AuthConfigs struct {
+	JWKSURL        string
+	HMACSecret     string
+	Issuer         string
+	Audience       []string
+	Algorithms     []string
+	ClockSkew      time.Duration
+	JWKSRefresh    time.Duration
+	JWKSMinRefresh time.Duration
+	ScopesClaim    string
+	RolesClaim     string
+	PolicyEngine   string
+	PolicyFile     string
+}
+
+type APIKeyConfigs struct {
+	Enabled          bool
+	Pepper           string
+	Hash             string
+	Prefix           string
+	CacheTTL         time.Duration
+	LastUsedInterval time.Duration
+}
+
+type OIDCConfigs struct {
+	IssuerURL             string
+	ClientID              string
+	ClientSecret          string
+	RedirectURL           string
+	Scopes                []string
+	PostLogoutRedirectURL string
+	// RolesClaim is AUTH_ROLES_CLAIM, read from the ID token claims of sessions
+	RolesClaim string
+}
+
+type RateLimitConfigs struct {
+	Enabled   bool
+	Store     string
+	Algorithm string
+	Limit     int
+	Window    time.Duration
+	Burst     int
+	KeyBy     []string
+	// AuthFailures is the number of failed authentications per Window and address
+	AuthFailures int
+}
+
+type SessionConfigs struct {
+	Store          string
+	CookieName     string
+	CookieSecret   string
+	CookieSecure   bool
+	CookieSameSite string
+	CookieDomain   string
+	TTL            time.Duration
+	IdleTimeout    time.Duration
+}
+
+type ProfilingConfigs struct {
+	Enabled       bool
+	Exporter      string
+	ServerURL     string
+	AuthToken     string
+	TenantID      string
+	Interval      time.Duration
+	Dir           string
+	MaxFiles      int
+	MutexFraction int
+}
+
 // AppConfigs holds all configs for the service
 type AppConfigs struct {
-	Server   *ServerConfigs
-	Database *DatabaseConfigs
+	Server    *ServerConfigs
+	System    *SystemConfigs
+	GRPC      *GRPCConfigs
+	Database  *DatabaseConfigs
+	Redis     *RedisConfigs
+	Cache     *CacheConfigs
+	Mail      *MailConfigs
+	Outbox    *OutboxConfigs
+	Profiling *ProfilingConfigs
+	Auth      *AuthConfigs
+	APIKeys   *APIKeyConfigs
+	OIDC      *OIDCConfigs
+	Session   *SessionConfigs
+	RateLimit *RateLimitConfigs
 }
 
 // GetAppConfigs loads all configs (server + db) and validates them
 func GetAppConfigs() (*AppConfigs, error) {
+	cfg := LoadAppConfigs()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadAppConfigs reads all configs from the environment without validating them
+func LoadAppConfigs() *AppConfigs {
 	serverCfg := &ServerConfigs{
 		Port:         fmt.Sprintf(":%s", getEnvOrDefault("PORT", "8080")),
 		ServiceName:  getEnvOrDefault("SERVICE_NAME", "go-chi-boilerplate"),
+		Environment:  strings.ToLower(getEnvOrDefault("APP_ENV", "production")),
 		LogLevel:     getEnvOrDefault("LOG_LEVEL", "info"),
 		OTLPEndpoint: getEnvOrDefault("OTLP_ENDPOINT", "otelcollector:4317"),
 	}
+	serverCfg.OpenAPIValidation = strings.ToLower(getEnvOrDefault("OPENAPI_VALIDATION", defaultOpenAPIValidation(serverCfg.Environment)))
+
+	systemCfg := &SystemConfigs{
+		Endpoints:    getEnvOrDefaultList("SYSTEM_ENDPOINTS", defaultSystemEndpoints(serverCfg.Environment)),
+		AuthUsername: getEnvOrDefault("SYSTEM_AUTH_USERNAME", ""),
+		AuthPassword: getEnvOrDefault("SYSTEM_AUTH_PASSWORD", ""),
+		AuthToken:    getEnvOrDefault("SYSTEM_AUTH_TOKEN", ""),
+		AllowedCIDRs: getEnvOrDefaultList("SYSTEM_ALLOWED_CIDRS", ""),
+	}
+	if slices.Equal(systemCfg.Endpoints, []string{"none"}) {
+		systemCfg.Endpoints = []string{}
+	}
+	if port := getEnvOrDefault("ADMIN_PORT", ""); port != "" {
+		systemCfg.AdminPort = fmt.Sprintf(":%s", port)
+	}
+
+	grpcCfg := &GRPCConfigs{
+		Enabled:           getEnvOrDefaultBool("GRPC_ENABLED", true),
+		Port:              fmt.Sprintf(":%s", getEnvOrDefault("GRPC_PORT", "9090")),
+		Reflection:        getEnvOrDefaultBool("GRPC_REFLECTION", true),
+		HealthInterval:    getEnvOrDefaultDuration("GRPC_HEALTH_INTERVAL", 5*time.Second),
+		GatewayEnabled:    getEnvOrDefaultBool("GRPC_GATEWAY_ENABLED", false),
+		GatewayOpenAPIDir: getEnvOrDefault("GRPC_GATEWAY_OPENAPI_DIR", "./docs/grpc"),
+	}
 
 	dbCfg := &DatabaseConfigs{
 		Host:         getEnvOrDefault("DB_HOST", ""),
@@ -54,14 +233,189 @@ func GetAppConfigs() (*AppConfigs, error) {
 		MaxLifetime:  getEnvOrDefaultDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
 	}
 
-	if err := dbCfg.Validate(); err != nil {
-		return nil, err
+	redisCfg := &RedisConfigs{
+		Addr:     getEnvOrDefault("REDIS_ADDR", ""),
+		Password: getEnvOrDefault("REDIS_PASSWORD", ""),
+		DB:       getEnvOrDefaultInt("REDIS_DB", 0),
+		PoolSize: getEnvOrDefaultInt("REDIS_POOL_SIZE", 10),
+	}
+
+	cacheCfg := &CacheConfigs{
+		LocalEnabled:        getEnvOrDefaultBool("CACHE_LOCAL_ENABLED", false),
+		LocalMaxEntries:     getEnvOrDefaultInt("CACHE_LOCAL_MAX_ENTRIES", 10000),
+		LocalTTL:            getEnvOrDefaultDuration("CACHE_LOCAL_TTL", 30*time.Second),
+		InvalidationChannel: getEnvOrDefault("CACHE_INVALIDATION_CHANNEL", "cache:invalidate"),
+	}
+
+	mailCfg := &MailConfigs{
+		Provider:      getEnvOrDefault("MAIL_PROVIDER", "log"),
+		From:          getEnvOrDefault("MAIL_FROM", "no-reply@example.com"),
+		SMTPHost:      getEnvOrDefault("SMTP_HOST", ""),
+		SMTPPort:      getEnvOrDefault("SMTP_PORT", "587"),
+		SMTPUsername:  getEnvOrDefault("SMTP_USERNAME", ""),
+		SMTPPassword:  getEnvOrDefault("SMTP_PASSWORD", ""),
+		SMTPStartTLS:  getEnvOrDefaultBool("SMTP_STARTTLS", true),
+		SMTPTimeout:   getEnvOrDefaultDuration("SMTP_TIMEOUT", 10*time.Second),
+		SMTPIdle:      getEnvOrDefaultDuration("SMTP_IDLE_TIMEOUT", 30*time.Second),
+		SinkDir:       getEnvOrDefault("MAIL_SINK_DIR", "./tmp/mail"),
+		DefaultLocale: getEnvOrDefault("MAIL_DEFAULT_LOCALE", "en"),
+	}
+
+	outboxCfg := &OutboxConfigs{
+		Enabled:      getEnvOrDefaultBool("OUTBOX_ENABLED", false),
+		PollInterval: getEnvOrDefaultDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		BatchSize:    getEnvOrDefaultInt("OUTBOX_BATCH_SIZE", 50),
+		MaxAttempts:  getEnvOrDefaultInt("OUTBOX_MAX_ATTEMPTS", 10),
+		BaseBackoff:  getEnvOrDefaultDuration("OUTBOX_BASE_BACKOFF", 5*time.Second),
+		MaxBackoff:   getEnvOrDefaultDuration("OUTBOX_MAX_BACKOFF", 15*time.Minute),
+	}
+
+	profilingCfg := &ProfilingConfigs{
+		Enabled:       getEnvOrDefaultBool("PROFILING_ENABLED", false),
+		Exporter:      strings.ToLower(getEnvOrDefault("PROFILING_EXPORTER", "pyroscope")),
+		ServerURL:     getEnvOrDefault("PROFILING_SERVER_URL", "http://pyroscope:4040"),
+		AuthToken:     getEnvOrDefault("PROFILING_AUTH_TOKEN", ""),
+		TenantID:      getEnvOrDefault("PROFILING_TENANT_ID", ""),
+		Interval:      getEnvOrDefaultDuration("PROFILING_INTERVAL", 15*time.Second),
+		Dir:           getEnvOrDefault("PROFILING_DIR", "./tmp/profiles"),
+		MaxFiles:      getEnvOrDefaultInt("PROFILING_DIR_MAX_FILES", 100),
+		MutexFraction: getEnvOrDefaultInt("PROFILING_MUTEX_FRACTION", 5),
+	}
+
+	authCfg := &AuthConfigs{
+		JWKSURL:        getEnvOrDefault("AUTH_JWKS_URL", ""),
+		HMACSecret:     getEnvOrDefault("AUTH_HMAC_SECRET", ""),
+		Issuer:         getEnvOrDefault("AUTH_ISSUER", ""),
+		Audience:       getEnvOrDefaultList("AUTH_AUDIENCE", ""),
+		Algorithms:     getEnvOrDefaultList("AUTH_ALGORITHMS", "RS256,ES256,EdDSA,HS256"),
+		ClockSkew:      getEnvOrDefaultDuration("AUTH_CLOCK_SKEW", 30*time.Second),
+		JWKSRefresh:    getEnvOrDefaultDuration("AUTH_JWKS_REFRESH_INTERVAL", 15*time.Minute),
+		JWKSMinRefresh: getEnvOrDefaultDuration("AUTH_JWKS_MIN_REFRESH_INTERVAL", time.Minute),
+		ScopesClaim:    getEnvOrDefault("AUTH_SCOPES_CLAIM", "scope"),
+		RolesClaim:     getEnvOrDefault("AUTH_ROLES_CLAIM", "roles"),
+		PolicyEngine:   strings.ToLower(getEnvOrDefault("AUTH_POLICY_ENGINE", "rbac")),
+		PolicyFile:     getEnvOrDefault("AUTH_POLICY_FILE", ""),
+	}
+
+	apiKeyCfg := &APIKeyConfigs{
+		Enabled:          getEnvOrDefaultBool("API_KEYS_ENABLED", false),
+		Pepper:           getEnvOrDefault("API_KEY_PEPPER", ""),
+		Hash:             strings.ToLower(getEnvOrDefault("API_KEY_HASH", "sha256")),
+		Prefix:           getEnvOrDefault("API_KEY_PREFIX", "sk"),
+		CacheTTL:         getEnvOrDefaultDuration("API_KEY_CACHE_TTL", 30*time.Second),
+		LastUsedInterval: getEnvOrDefaultDuration("API_KEY_LAST_USED_INTERVAL", time.Minute),
+	}
+
+	oidcCfg := &OIDCConfigs{
+		IssuerURL:             getEnvOrDefault("OIDC_ISSUER_URL", ""),
+		ClientID:              getEnvOrDefault("OIDC_CLIENT_ID", ""),
+		ClientSecret:          getEnvOrDefault("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:           getEnvOrDefault("OIDC_REDIRECT_URL", ""),
+		Scopes:                getEnvOrDefaultList("OIDC_SCOPES", "openid,profile,email"),
+		PostLogoutRedirectURL: getEnvOrDefault("OIDC_POST_LOGOUT_REDIRECT_URL", ""),
+		RolesClaim:            authCfg.RolesClaim,
+	}
+
+	sessionCfg := &SessionConfigs{
+		Store:          strings.ToLower(getEnvOrDefault("SESSION_STORE", "cookie")),
+		CookieName:     getEnvOrDefault("SESSION_COOKIE_NAME", "session"),
+		CookieSecret:   getEnvOrDefault("SESSION_COOKIE_SECRET", ""),
+		CookieSecure:   getEnvOrDefaultBool("SESSION_COOKIE_SECURE", true),
+		CookieSameSite: strings.ToLower(getEnvOrDefault("SESSION_COOKIE_SAMESITE", "lax")),
+		CookieDomain:   getEnvOrDefault("SESSION_COOKIE_DOMAIN", ""),
+		TTL:            getEnvOrDefaultDuration("SESSION_TTL", 24*time.Hour),
+		IdleTimeout:    getEnvOrDefaultDuration("SESSION_IDLE_TIMEOUT", 30*time.Minute),
+	}
+
+	rateLimitCfg := &RateLimitConfigs{
+		Enabled:   getEnvOrDefaultBool("RATE_LIMIT_ENABLED", false),
+		Store:     strings.ToLower(getEnvOrDefault("RATE_LIMIT_STORE", "memory")),
+		Algorithm: strings.ToLower(getEnvOrDefault("RATE_LIMIT_ALGORITHM", "token_bucket")),
+		Limit:     getEnvOrDefaultInt("RATE_LIMIT_REQUESTS", 100),
+		Window:    getEnvOrDefaultDuration("RATE_LIMIT_WINDOW", time.Minute),
+		Burst:     getEnvOrDefaultInt("RATE_LIMIT_BURST", 0),
+		KeyBy:     getEnvOrDefaultList("RATE_LIMIT_KEY", "ip"),
+
+		AuthFailures: getEnvOrDefaultInt("RATE_LIMIT_AUTH_FAILURES", 10),
 	}
 
 	return &AppConfigs{
-		Server:   serverCfg,
-		Database: dbCfg,
-	}, nil
+		Server:    serverCfg,
+		System:    systemCfg,
+		GRPC:      grpcCfg,
+		Database:  dbCfg,
+		Redis:     redisCfg,
+		Cache:     cacheCfg,
+		Mail:      mailCfg,
+		Outbox:    outboxCfg,
+		Profiling: profilingCfg,
+		Auth:      authCfg,
+		APIKeys:   apiKeyCfg,
+		OIDC:      oidcCfg,
+		Session:   sessionCfg,
+		RateLimit: rateLimitCfg,
+	}
+}
+
+// Validate checks every config section
+func (a *AppConfigs) Validate() error {
+	if err := a.Server.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.System.Validate(a.Server); err != nil {
+		return err
+	}
+
+	if err := a.GRPC.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.Database.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.Cache.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.Mail.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.Outbox.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.Profiling.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.Auth.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.APIKeys.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.OIDC.Validate(); err != nil {
+		return err
+	}
+
+	if a.Session.Store == "redis" && !a.Redis.Enabled() {
+		return errors.New("session configuration is incomplete: SESSION_STORE=redis requires REDIS_ADDR")
+	}
+
+	if err := a.Session.Validate(a.OIDC.Enabled()); err != nil {
+		return err
+	}
+
+	if a.RateLimit.Enabled && a.RateLimit.Store == "redis" && !a.Redis.Enabled() {
+		return errors.New("rate limit configuration is incomplete: RATE_LIMIT_STORE=redis requires REDIS_ADDR")
+	}
+
+	return a.RateLimit.Validate()
 }
 
 // Validate checks if required DB configs are present
@@ -72,6 +426,319 @@ func (d *DatabaseConfigs) Validate() error {
 	return nil
 }
 
+// Validate checks the server options that have a fixed set of values
+func (s *ServerConfigs) Validate() error {
+	switch s.OpenAPIValidation {
+	case "enforce", "log", "off":
+		return nil
+	default:
+		return fmt.Errorf("server configuration is invalid: OPENAPI_VALIDATION must be enforce, log or off, got %q", s.OpenAPIValidation)
+	}
+}
+
+// defaultOpenAPIValidation enforces the API schema in development and test and only
+// logs violations elsewhere
+func defaultOpenAPIValidation(environment string) string {
+	if environment == "development" || environment == "test" {
+		return "enforce"
+	}
+	return "log"
+}
+
+// systemEndpoints lists the optional /system endpoints that SYSTEM_ENDPOINTS can enable
+var systemEndpoints = []string{"info", "metrics", "openapi", "swagger", "email_preview", "debug"}
+
+// defaultSystemEndpoints exposes documentation, email previews and debug endpoints
+// outside production only
+func defaultSystemEndpoints(environment string) string {
+	switch environment {
+	case "development":
+		return "info,metrics,openapi,swagger,email_preview,debug"
+	case "test":
+		return "info,metrics,openapi,swagger"
+	default:
+		return "info,metrics"
+	}
+}
+
+// Validate checks the endpoint names, the credentials and the allowlist, and that the
+// debug endpoint is protected outside development
+func (s *SystemConfigs) Validate(server *ServerConfigs) error {
+	if s.AdminPort != "" && s.AdminPort == server.Port {
+		return errors.New("system configuration is invalid: ADMIN_PORT must differ from PORT")
+	}
+
+	for _, name := range s.Endpoints {
+		if !slices.Contains(systemEndpoints, name) {
+			return fmt.Errorf("system configuration is invalid: unknown endpoint %q in SYSTEM_ENDPOINTS, expected one of %s", name, strings.Join(systemEndpoints, ", "))
+		}
+	}
+
+	if (s.AuthUsername == "") != (s.AuthPassword == "") {
+		return errors.New("system configuration is incomplete: SYSTEM_AUTH_USERNAME and SYSTEM_AUTH_PASSWORD must be set together")
+	}
+
+	if _, err := s.AllowedPrefixes(); err != nil {
+		return fmt.Errorf("system configuration is invalid: SYSTEM_ALLOWED_CIDRS: %w", err)
+	}
+
+	// Heap dumps and traces expose memory contents, so they must not be public
+	unprotected := s.AuthUsername == "" && s.AuthToken == "" && len(s.AllowedCIDRs) == 0
+	if s.Enabled("debug") && !server.IsDevelopment() && unprotected {
+		return errors.New("system configuration is incomplete: the debug endpoint outside development requires SYSTEM_AUTH_USERNAME/SYSTEM_AUTH_PASSWORD, SYSTEM_AUTH_TOKEN or SYSTEM_ALLOWED_CIDRS")
+	}
+	return nil
+}
+
+// Enabled reports whether the optional /system endpoint name is enabled
+func (s *SystemConfigs) Enabled(name string) bool {
+	return slices.Contains(s.Endpoints, name)
+}
+
+// AllowedPrefixes parses SYSTEM_ALLOWED_CIDRS; a bare IP allows that single address
+func (s *SystemConfigs) AllowedPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(s.AllowedCIDRs))
+	for _, cidr := range s.AllowedCIDRs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// IsDevelopment reports whether the service runs with APP_ENV=development
+func (s *ServerConfigs) IsDevelopment() bool {
+	return s.Environment == "development"
+}
+
+// Enabled reports whether a Redis address has been configured
+func (r *RedisConfigs) Enabled() bool {
+	return r.Addr != ""
+}
+
+// Validate checks that the health check interval is usable
+func (g *GRPCConfigs) Validate() error {
+	if g.Enabled && g.HealthInterval <= 0 {
+		return errors.New("grpc configuration is invalid: GRPC_HEALTH_INTERVAL must be positive")
+	}
+	return nil
+}
+
+// Validate checks that the local cache tier bounds are usable
+func (c *CacheConfigs) Validate() error {
+	if c.LocalEnabled && (c.LocalMaxEntries <= 0 || c.LocalTTL <= 0) {
+		return errors.New("cache configuration is invalid: CACHE_LOCAL_MAX_ENTRIES and CACHE_LOCAL_TTL must be positive")
+	}
+	return nil
+}
+
+// Validate checks that the selected mail provider has what it needs
+func (m *MailConfigs) Validate() error {
+	if m.Provider == "smtp" && m.SMTPHost == "" {
+		return errors.New("mail configuration is incomplete: SMTP_HOST is required when MAIL_PROVIDER=smtp")
+	}
+	return nil
+}
+
+// Validate checks the polling and retry settings of the outbox dispatcher
+func (o *OutboxConfigs) Validate() error {
+	if !o.Enabled {
+		return nil
+	}
+
+	if o.PollInterval <= 0 || o.BatchSize <= 0 {
+		return errors.New("outbox configuration is invalid: OUTBOX_POLL_INTERVAL and OUTBOX_BATCH_SIZE must be positive")
+	}
+	if o.MaxAttempts <= 0 {
+		return errors.New("outbox configuration is invalid: OUTBOX_MAX_ATTEMPTS must be positive")
+	}
+	if o.BaseBackoff <= 0 || o.MaxBackoff < o.BaseBackoff {
+		return errors.New("outbox configuration is invalid: OUTBOX_BASE_BACKOFF must be positive and OUTBOX_MAX_BACKOFF at least as long")
+	}
+	return nil
+}
+
+// Validate checks the exporter settings when continuous profiling is enabled
+func (p *ProfilingConfigs) Validate() error {
+	if !p.Enabled {
+		return nil
+	}
+
+	switch p.Exporter {
+	case "pyroscope":
+		if p.ServerURL == "" {
+			return errors.New("profiling configuration is incomplete: PROFILING_SERVER_URL is required when PROFILING_EXPORTER=pyroscope")
+		}
+	case "dir":
+		if p.Dir == "" || p.MaxFiles <= 0 {
+			return errors.New("profiling configuration is invalid: PROFILING_DIR must be set and PROFILING_DIR_MAX_FILES must be positive")
+		}
+	default:
+		return fmt.Errorf("profiling configuration is invalid: PROFILING_EXPORTER must be pyroscope or dir, got %q", p.Exporter)
+	}
+
+	if p.Interval < time.Second {
+		return errors.New("profiling configuration is invalid: PROFILING_INTERVAL must be at least 1s")
+	}
+	return nil
+}
+
+// jwtAlgorithms lists the signing algorithms AUTH_ALGORITHMS accepts
+var jwtAlgorithms = []string{"RS256", "ES256", "EdDSA", "HS256"}
+
+// Enabled reports whether a JWKS URL or an HMAC secret has been configured
+func (a *AuthConfigs) Enabled() bool {
+	return a.JWKSURL != "" || a.HMACSecret != ""
+}
+
+// Validate checks the policy engine, and the algorithms and refresh intervals when
+// authentication is enabled
+func (a *AuthConfigs) Validate() error {
+	if a.PolicyEngine != "rbac" && a.PolicyEngine != "cel" {
+		return fmt.Errorf("auth configuration is invalid: AUTH_POLICY_ENGINE must be rbac or cel, got %q", a.PolicyEngine)
+	}
+
+	if !a.Enabled() {
+		return nil
+	}
+
+	for _, alg := range a.Algorithms {
+		if !slices.Contains(jwtAlgorithms, alg) {
+			return fmt.Errorf("auth configuration is invalid: unsupported algorithm %q in AUTH_ALGORITHMS, expected one of %s", alg, strings.Join(jwtAlgorithms, ", "))
+		}
+	}
+
+	if a.HMACSecret != "" && len(a.HMACSecret) < 32 {
+		return errors.New("auth configuration is invalid: AUTH_HMAC_SECRET must be at least 32 bytes")
+	}
+
+	if a.JWKSURL != "" && (a.JWKSRefresh <= 0 || a.JWKSMinRefresh <= 0) {
+		return errors.New("auth configuration is invalid: AUTH_JWKS_REFRESH_INTERVAL and AUTH_JWKS_MIN_REFRESH_INTERVAL must be positive")
+	}
+	return nil
+}
+
+// Validate checks the hash algorithm and key prefix, and requires a pepper when API
+// keys are enabled
+func (k *APIKeyConfigs) Validate() error {
+	if k.Hash != "sha256" && k.Hash != "argon2id" {
+		return fmt.Errorf("api key configuration is invalid: API_KEY_HASH must be sha256 or argon2id, got %q", k.Hash)
+	}
+
+	if k.Prefix == "" || strings.Trim(k.Prefix, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") != "" {
+		return errors.New("api key configuration is invalid: API_KEY_PREFIX must be non-empty and alphanumeric")
+	}
+
+	if (k.Enabled || k.Pepper != "") && len(k.Pepper) < 32 {
+		return errors.New("api key configuration is incomplete: API_KEY_PEPPER must be at least 32 bytes")
+	}
+
+	if k.CacheTTL < 0 || k.LastUsedInterval < 0 {
+		return errors.New("api key configuration is invalid: API_KEY_CACHE_TTL and API_KEY_LAST_USED_INTERVAL must not be negative")
+	}
+	return nil
+}
+
+// Enabled reports whether an OIDC issuer has been configured
+func (o *OIDCConfigs) Enabled() bool {
+	return o.IssuerURL != ""
+}
+
+// Validate checks that the client is fully configured when an issuer is set
+func (o *OIDCConfigs) Validate() error {
+	if !o.Enabled() {
+		return nil
+	}
+
+	if o.ClientID == "" || o.RedirectURL == "" {
+		return errors.New("oidc configuration is incomplete: OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required with OIDC_ISSUER_URL")
+	}
+
+	for _, env := range [][2]string{{"OIDC_ISSUER_URL", o.IssuerURL}, {"OIDC_REDIRECT_URL", o.RedirectURL}} {
+		name, raw := env[0], env[1]
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("oidc configuration is invalid: %s must be an absolute http(s) URL, got %q", name, raw)
+		}
+	}
+
+	if !slices.Contains(o.Scopes, "openid") {
+		return errors.New("oidc configuration is invalid: OIDC_SCOPES must include openid")
+	}
+	return nil
+}
+
+// sessionStores lists the values SESSION_STORE accepts
+var sessionStores = []string{"cookie", "memory", "postgres", "redis"}
+
+// Validate checks the store and cookie settings; a cookie secret is required when
+// sessions are used, i.e. when OIDC is enabled
+func (s *SessionConfigs) Validate(required bool) error {
+	if !slices.Contains(sessionStores, s.Store) {
+		return fmt.Errorf("session configuration is invalid: SESSION_STORE must be one of %s, got %q", strings.Join(sessionStores, ", "), s.Store)
+	}
+
+	if s.CookieName == "" || strings.ContainsAny(s.CookieName, " ;,=") {
+		return fmt.Errorf("session configuration is invalid: SESSION_COOKIE_NAME %q is not a valid cookie name", s.CookieName)
+	}
+
+	// Browsers reject __Host- cookies that are not Secure or carry a Domain
+	if strings.HasPrefix(s.CookieName, "__Host-") && (!s.CookieSecure || s.CookieDomain != "") {
+		return errors.New("session configuration is invalid: a __Host- cookie needs SESSION_COOKIE_SECURE=true and no SESSION_COOKIE_DOMAIN")
+	}
+
+	if s.CookieSameSite != "lax" && s.CookieSameSite != "strict" {
+		return fmt.Errorf("session configuration is invalid: SESSION_COOKIE_SAMESITE must be lax or strict, got %q", s.CookieSameSite)
+	}
+
+	if (required || s.CookieSecret != "") && len(s.CookieSecret) < 32 {
+		return errors.New("session configuration is incomplete: SESSION_COOKIE_SECRET must be at least 32 bytes")
+	}
+
+	if s.TTL <= 0 || s.IdleTimeout < 0 {
+		return errors.New("session configuration is invalid: SESSION_TTL must be positive and SESSION_IDLE_TIMEOUT not negative")
+	}
+	return nil
+}
+
+// rateLimitKeys lists the values RATE_LIMIT_KEY combines
+var rateLimitKeys = []string{"ip", "api_key", "user", "route"}
+
+// Validate checks the store, algorithm, quota and keys of the /api rate limit
+func (l *RateLimitConfigs) Validate() error {
+	if l.Store != "memory" && l.Store != "redis" {
+		return fmt.Errorf("rate limit configuration is invalid: RATE_LIMIT_STORE must be memory or redis, got %q", l.Store)
+	}
+
+	if l.Algorithm != "token_bucket" && l.Algorithm != "sliding_window" {
+		return fmt.Errorf("rate limit configuration is invalid: RATE_LIMIT_ALGORITHM must be token_bucket or sliding_window, got %q", l.Algorithm)
+	}
+
+	if l.Limit <= 0 || l.AuthFailures <= 0 || l.Window < time.Millisecond || l.Burst < 0 {
+		return errors.New("rate limit configuration is invalid: RATE_LIMIT_REQUESTS and RATE_LIMIT_AUTH_FAILURES must be positive, RATE_LIMIT_WINDOW at least 1ms and RATE_LIMIT_BURST not negative")
+	}
+
+	if len(l.KeyBy) == 0 {
+		return errors.New("rate limit configuration is invalid: RATE_LIMIT_KEY must not be empty")
+	}
+	for _, key := range l.KeyBy {
+		if !slices.Contains(rateLimitKeys, key) {
+			return fmt.Errorf("rate limit configuration is invalid: RATE_LIMIT_KEY must combine %s, got %q", strings.Join(rateLimitKeys, ", "), key)
+		}
+	}
+	return nil
+}
+
 // getEnvOrDefault returns the value of an environment variable or a default
 func getEnvOrDefault(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -106,3 +773,28 @@ func getEnvOrDefaultDuration(key string, defaultValue time.Duration) time.Durati
 	}
 	return val
 }
+
+// getEnvOrDefaultList reads a comma-separated list from env or splits the default;
+// empty items are dropped
+func getEnvOrDefaultList(key, defaultValue string) []string {
+	items := []string{}
+	for _, item := range strings.Split(getEnvOrDefault(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvOrDefaultBool reads a bool from env or returns a default
+func getEnvOrDefaultBool(key string, defaultValue bool) bool {
+	valStr := os.Getenv(key)
+	if valStr == "" {
+		return defaultValue
+	}
+	val, err := strconv.ParseBool(valStr)
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
@@ -0,0 +1,122 @@
+package apperrors
+
+import (
+	"errors"
+	"time"
+)
+
+// Kind classifies an application error; adapters map it to HTTP and gRPC status codes
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindBadRequest
+	KindValidation
+	KindConflict
+	KindUnauthorized
+	KindForbidden
+	KindRateLimited
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not_found"
+	case KindBadRequest:
+		return "bad_request"
+	case KindValidation:
+		return "validation"
+	case KindConflict:
+		return "conflict"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindForbidden:
+		return "forbidden"
+	case KindRateLimited:
+		return "rate_limited"
+	default:
+		return "internal"
+	}
+}
+
+// Error is an application error. Code and Message are safe to return to callers;
+// Err is the internal cause and is only logged.
+type Error struct {
+	Kind Kind
+	// Code is a stable machine-readable identifier, e.g. "user_not_found"
+	Code    string
+	Message string
+	// Fields holds per-field messages of a validation error
+	Fields map[string]string
+	// RetryAfter tells rate limited callers when to retry
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns a copy of e with err as its internal cause
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// NotFound reports a missing resource
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+// BadRequest reports a request that cannot be read, e.g. malformed or oversized bodies
+func BadRequest(code, message string) *Error {
+	return &Error{Kind: KindBadRequest, Code: code, Message: message}
+}
+
+// Validation reports invalid input; fields maps input names to messages and may be nil
+func Validation(code, message string, fields map[string]string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+// Conflict reports a request that conflicts with the current state, e.g. a duplicate
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// Unauthorized reports missing or invalid credentials
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+// Forbidden reports an authenticated caller lacking permission
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+// RateLimited reports a caller over its quota
+func RateLimited(message string, retryAfter time.Duration) *Error {
+	return &Error{Kind: KindRateLimited, Code: "rate_limited", Message: message, RetryAfter: retryAfter}
+}
+
+// Internal hides err behind a generic message
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: "internal", Message: "internal server error", Err: err}
+}
+
+// From returns the *Error in err's chain, or err wrapped as Internal
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
@@ -0,0 +1,27 @@
+package auth
+
+// Request asks whether the principal may perform action on a resource
+type Request struct {
+	Principal *Principal
+	// Action names the operation, e.g. "orders:update"
+	Action string
+	// Resource holds attributes of the target the policy can inspect, e.g. its owner
+	Resource map[string]any
+}
+
+// Decision is the outcome of a policy evaluation
+type Decision struct {
+	Allowed bool
+	// Reason explains the decision for logs, e.g. the role or rule that granted it
+	Reason string
+}
+
+// Allow returns an allowing Decision
+func Allow(reason string) Decision {
+	return Decision{Allowed: true, Reason: reason}
+}
+
+// Deny returns a denying Decision
+func Deny(reason string) Decision {
+	return Decision{Reason: reason}
+}
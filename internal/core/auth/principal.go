@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller, e.g. the sub claim of a JWT
+	Subject string
+	Issuer  string
+	// Audience lists the audiences the credential was issued for
+	Audience []string
+	Scopes   []string
+	Roles    []string
+	// ExpiresAt is when the credential expires; zero when it doesn't
+	ExpiresAt time.Time
+	// Method is how the caller authenticated, e.g. "jwt"
+	Method string
+	// Claims holds every claim of the credential, including the ones above
+	Claims map[string]any
+}
+
+// HasScope reports whether the principal was granted scope
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// HasRole reports whether the principal has role
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,27 @@
+package auth
+
+import "context"
+
+// User is the person signed in to a browser session
+type User struct {
+	// Subject is the sub claim of the ID token
+	Subject string
+	Issuer  string
+	Email   string
+	Name    string
+	// Claims holds every claim of the ID token
+	Claims map[string]any
+}
+
+type userKey struct{}
+
+// WithUser returns a copy of ctx carrying the signed-in user
+func WithUser(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, userKey{}, u)
+}
+
+// CurrentUser returns the signed-in user stored in ctx, if any
+func CurrentUser(ctx context.Context) (*User, bool) {
+	u, ok := ctx.Value(userKey{}).(*User)
+	return u, ok && u != nil
+}
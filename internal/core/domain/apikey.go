@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// APIKey is a credential issued to a machine client. Only a hash of the secret is
+// stored; Prefix is the public part of the key used to look it up.
+type APIKey struct {
+	ID     string
+	Name   string
+	Prefix string
+	Hash   string
+	Scopes []string
+
+	CreatedAt time.Time
+	// ExpiresAt is nil for keys that don't expire
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+// Active reports whether the key is neither revoked nor expired at now
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
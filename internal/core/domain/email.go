@@ -0,0 +1,32 @@
+package domain
+
+// Attachment is a file sent along with an Email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	// ContentID marks the attachment as inline so HTML bodies can reference it as cid:<ContentID>
+	ContentID string
+}
+
+// Email is a message handed to a Mailer; at least one of Text or HTML must be set
+type Email struct {
+	From        string
+	To          []string
+	Cc          []string
+	Bcc         []string
+	ReplyTo     string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+	Headers     map[string]string
+}
+
+// Recipients returns every envelope recipient, including Bcc
+func (e *Email) Recipients() []string {
+	rcpt := make([]string, 0, len(e.To)+len(e.Cc)+len(e.Bcc))
+	rcpt = append(rcpt, e.To...)
+	rcpt = append(rcpt, e.Cc...)
+	return append(rcpt, e.Bcc...)
+}
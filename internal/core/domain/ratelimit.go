@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// RateLimitAlgorithm selects how a RateLimit counts requests
+type RateLimitAlgorithm string
+
+const (
+	// TokenBucket refills Limit tokens per Window into a bucket holding up to Burst,
+	// so short bursts pass while the average rate stays at Limit per Window
+	TokenBucket RateLimitAlgorithm = "token_bucket"
+	// SlidingWindow allows Limit requests in any Window; the previous window is
+	// weighted by how much of it still overlaps, which avoids bursts at window edges
+	SlidingWindow RateLimitAlgorithm = "sliding_window"
+)
+
+// RateLimit is a quota of Limit requests per Window
+type RateLimit struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+	// Burst is the bucket size of TokenBucket; zero means Limit
+	Burst int
+}
+
+// Capacity is the number of requests that may be made at once
+func (l RateLimit) Capacity() int {
+	if l.Algorithm == TokenBucket && l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Limit
+}
+
+// RateLimitResult is the outcome of counting one request against a RateLimit
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// Reset is the time until the whole quota is available again
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed; zero when Allowed
+	RetryAfter time.Duration
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// Session is the server-side state of a browser session
+type Session struct {
+	// ID is the storage key; stores never see the session ID the browser holds
+	ID string
+	// Data is the JSON encoded session value
+	Data []byte
+
+	CreatedAt    time.Time
+	LastActiveAt time.Time
+	// ExpiresAt is when the session ends unless it is used again before
+	ExpiresAt time.Time
+}
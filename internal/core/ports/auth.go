@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/core/auth"
+	"go-chi-boilerplate/internal/core/domain"
+	"time"
+)
+
+// TokenVerifier is the port implemented by bearer token validators
+type TokenVerifier interface {
+	// Verify checks the token and returns its principal; invalid tokens return an
+	// apperrors.Unauthorized error
+	Verify(ctx context.Context, token string) (*auth.Principal, error)
+}
+
+// PolicyEngine is the port implemented by authorization policy evaluators
+type PolicyEngine interface {
+	// Evaluate decides whether req is allowed; an error means the policy could not be
+	// evaluated and the request must be denied
+	Evaluate(ctx context.Context, req auth.Request) (auth.Decision, error)
+}
+
+// APIKeyRepository is the port implemented by API key stores
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	// GetByPrefix returns the key with the given lookup prefix, or an
+	// apperrors.NotFound error
+	GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	// List returns every key, newest first
+	List(ctx context.Context) ([]domain.APIKey, error)
+	// Revoke marks the key as revoked; revoking an unknown key returns an
+	// apperrors.NotFound error
+	Revoke(ctx context.Context, id string) error
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCacheMiss is returned by Cache.Get when the key is not present
+var ErrCacheMiss = errors.New("cache: key not found")
+
+// Cache is the port implemented by key/value cache adapters
+type Cache interface {
+	// Get returns the value stored under key or ErrCacheMiss
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key; a zero ttl means no expiry
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the given keys, ignoring keys that do not exist
+	Delete(ctx context.Context, keys ...string) error
+}
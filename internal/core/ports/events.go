@@ -0,0 +1,8 @@
+package ports
+
+import "context"
+
+// EventPublisher is the port implemented by message broker adapters
+type EventPublisher interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+}
@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/core/domain"
+)
+
+// Mailer is the port implemented by email delivery adapters
+type Mailer interface {
+	Send(ctx context.Context, email *domain.Email) error
+}
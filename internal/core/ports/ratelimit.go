@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"go-chi-boilerplate/internal/core/domain"
+)
+
+// RateLimiter is the port implemented by rate limit stores
+type RateLimiter interface {
+	// Allow counts one request against limit for key and reports whether it may proceed
+	Allow(ctx context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error)
+	// Peek reports whether a request for key would be allowed, without counting it
+	Peek(ctx context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error)
+}
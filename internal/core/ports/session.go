@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"go-chi-boilerplate/internal/core/domain"
+)
+
+// ErrSessionNotFound is returned by SessionStore.Get for unknown and expired sessions
+var ErrSessionNotFound = errors.New("session: not found")
+
+// SessionStore is the port implemented by server-side session stores
+type SessionStore interface {
+	// Get returns the session stored under id or ErrSessionNotFound
+	Get(ctx context.Context, id string) (*domain.Session, error)
+	// Save creates or replaces the session; stores may drop it after s.ExpiresAt
+	Save(ctx context.Context, s *domain.Session) error
+	// Delete removes the session, ignoring sessions that do not exist
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,94 @@
+package meta
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Set at build time, e.g.
+//
+//	-ldflags "-X go-chi-boilerplate/internal/meta.version=v1.2.3 -X go-chi-boilerplate/internal/meta.commit=abc1234"
+//
+// Anything left empty falls back to the VCS information embedded by the Go toolchain.
+var (
+	version   string
+	commit    string
+	buildDate string
+	dirty     string
+)
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Dirty     bool   `json:"dirty"`
+}
+
+var (
+	buildInfo     BuildInfo
+	buildInfoOnce sync.Once
+)
+
+// Build returns the build metadata from ldflags, falling back to debug.ReadBuildInfo
+func Build() BuildInfo {
+	buildInfoOnce.Do(func() {
+		buildInfo = BuildInfo{
+			Version:   version,
+			Commit:    commit,
+			BuildDate: buildDate,
+			GoVersion: runtime.Version(),
+		}
+		buildInfo.Dirty, _ = strconv.ParseBool(dirty)
+
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			if buildInfo.Version == "" && bi.Main.Version != "(devel)" {
+				buildInfo.Version = bi.Main.Version
+			}
+			for _, s := range bi.Settings {
+				switch s.Key {
+				case "vcs.revision":
+					if buildInfo.Commit == "" {
+						buildInfo.Commit = s.Value
+					}
+				case "vcs.time":
+					if buildInfo.BuildDate == "" {
+						buildInfo.BuildDate = s.Value
+					}
+				case "vcs.modified":
+					if dirty == "" {
+						buildInfo.Dirty = s.Value == "true"
+					}
+				}
+			}
+		}
+
+		if buildInfo.Version == "" {
+			buildInfo.Version = "dev"
+		}
+		if buildInfo.Commit == "" {
+			buildInfo.Commit = "unknown"
+		}
+		if buildInfo.BuildDate == "" {
+			buildInfo.BuildDate = "unknown"
+		}
+	})
+	return buildInfo
+}
+
+// Version returns the version of the running binary
+func Version() string {
+	return Build().Version
+}
+
+// startTime is when the process started, used to report uptime
+var startTime = time.Now()
+
+// Uptime returns how long the process has been running
+func Uptime() time.Duration {
+	return time.Since(startTime)
+}
@@ -1,6 +1,7 @@
 package meta
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"strings"
@@ -32,3 +33,18 @@ func Fatal(logger *slog.Logger, msg string, args ...any) {
 	logger.Error(msg, args...)
 	os.Exit(1)
 }
+
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx carrying the request-scoped logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFromContext returns the logger stored in ctx, or slog.Default()
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -1,13 +1,25 @@
 package meta
 
 import (
+	"errors"
 	"go-chi-boilerplate/internal/adapters/secondary/database/postgresql"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 var (
+	// Build metadata, exposed as labels on a constant 1
+	BuildInfoGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "build_info",
+			Help: "Build metadata of the running binary; the value is always 1",
+		},
+		[]string{"version", "commit", "build_date", "go_version", "dirty"},
+	)
+
 	// HTTP metrics
 	HTTPRequestsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -26,6 +38,46 @@ var (
 		[]string{"method", "path"},
 	)
 
+	HTTPDeprecatedRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_deprecated_requests_total",
+			Help: "Total number of requests served by deprecated API versions or routes",
+		},
+		[]string{"version", "method", "route"},
+	)
+
+	HTTPRateLimitedRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_rate_limited_requests_total",
+			Help: "Total number of HTTP requests rejected by the rate limiter",
+		},
+		[]string{"method", "route"},
+	)
+	HTTPRateLimiterErrorsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "http_rate_limiter_errors_total",
+			Help: "Total number of requests let through because the rate limit store failed",
+		},
+	)
+
+	// gRPC metrics
+	GRPCServerHandledTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Total number of RPCs completed on the server, regardless of success or failure",
+		},
+		[]string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"},
+	)
+
+	GRPCServerHandlingSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "gRPC request duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"grpc_type", "grpc_service", "grpc_method"},
+	)
+
 	// PostgreSQL database metrics
 	PostgresDBOpenConns = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -45,11 +97,104 @@ var (
 			Help: "Maximum allowed open connections to the PostgreSQL database",
 		},
 	)
+
+	// Cache metrics; hit ratio per tier is hits / (hits + misses)
+	CacheRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_requests_total",
+			Help: "Total number of cache lookups by tier (local, remote) and result (hit, miss, error)",
+		},
+		[]string{"tier", "result"},
+	)
+	CacheLocalEntries = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cache_local_entries",
+			Help: "Number of entries held in the in-process cache tier",
+		},
+	)
+	CacheInvalidationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_invalidations_total",
+			Help: "Total number of local cache keys invalidated by source (local, remote)",
+		},
+		[]string{"source"},
+	)
+
+	// Transactional outbox metrics
+	OutboxMessagesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_messages_total",
+			Help: "Total number of outbox delivery attempts by topic and result (delivered, retry, dead)",
+		},
+		[]string{"topic", "result"},
+	)
+	OutboxDeliveryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "outbox_delivery_duration_seconds",
+			Help:    "Outbox message delivery duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"topic"},
+	)
+	OutboxPendingMessages = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "outbox_pending_messages",
+			Help: "Number of outbox messages waiting to be delivered",
+		},
+	)
+	OutboxLeader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "outbox_leader",
+			Help: "1 if this replica holds the outbox dispatcher lock, 0 otherwise",
+		},
+	)
+
+	// Authorization metrics
+	AuthzDecisionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "authz_decisions_total",
+			Help: "Total number of authorization decisions, by mechanism (scopes, roles, policy), requirement and decision",
+		},
+		[]string{"mechanism", "requirement", "decision"},
+	)
+
+	// Profiling metrics
+	ProfilesExportedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "profiles_exported_total",
+			Help: "Total number of continuous profiles exported, by kind and result",
+		},
+		[]string{"kind", "result"},
+	)
 )
 
 // InitMetrics registers all metrics with Prometheus
 func InitMetrics() {
-	prometheus.MustRegister(HTTPRequestsTotal, HTTPRequestDuration)
+	prometheus.MustRegister(BuildInfoGauge, HTTPRequestsTotal, HTTPRequestDuration, HTTPDeprecatedRequestsTotal)
+	initRuntimeMetrics()
+
+	bi := Build()
+	BuildInfoGauge.WithLabelValues(bi.Version, bi.Commit, bi.BuildDate, bi.GoVersion, strconv.FormatBool(bi.Dirty)).Set(1)
+}
+
+// initRuntimeMetrics swaps the default Go collector for one that also exports the GC,
+// memory and scheduler metrics of runtime/metrics, and registers the process collector
+// if the default registry doesn't have it yet
+func initRuntimeMetrics() {
+	prometheus.Unregister(collectors.NewGoCollector())
+	prometheus.MustRegister(collectors.NewGoCollector(
+		collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsGC, collectors.MetricsMemory, collectors.MetricsScheduler),
+	))
+
+	err := prometheus.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	if are := (prometheus.AlreadyRegisteredError{}); err != nil && !errors.As(err, &are) {
+		panic(err)
+	}
+}
+
+// InitGRPCMetrics registers gRPC server metrics with Prometheus
+func InitGRPCMetrics() {
+	prometheus.MustRegister(GRPCServerHandledTotal, GRPCServerHandlingSeconds)
 }
 
 // InitDBMetrics registers PostgreSQL metrics and updates them periodically
@@ -75,3 +220,28 @@ func InitDBMetrics(db *postgresql.PostgresDB) {
 		}
 	}()
 }
+
+// InitCacheMetrics registers cache metrics with Prometheus
+func InitCacheMetrics() {
+	prometheus.MustRegister(CacheRequestsTotal, CacheLocalEntries, CacheInvalidationsTotal)
+}
+
+// InitOutboxMetrics registers outbox dispatcher metrics with Prometheus
+func InitOutboxMetrics() {
+	prometheus.MustRegister(OutboxMessagesTotal, OutboxDeliveryDuration, OutboxPendingMessages, OutboxLeader)
+}
+
+// InitProfilingMetrics registers continuous profiling metrics with Prometheus
+func InitProfilingMetrics() {
+	prometheus.MustRegister(ProfilesExportedTotal)
+}
+
+// InitRateLimitMetrics registers rate limiting metrics with Prometheus
+func InitRateLimitMetrics() {
+	prometheus.MustRegister(HTTPRateLimitedRequestsTotal, HTTPRateLimiterErrorsTotal)
+}
+
+// InitAuthMetrics registers authorization metrics with Prometheus
+func InitAuthMetrics() {
+	prometheus.MustRegister(AuthzDecisionsTotal)
+}
@@ -0,0 +1,230 @@
+package meta
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go-chi-boilerplate/internal/config"
+	"log/slog"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// SpanIDLabel is the pprof label that carries the span ID of the request being profiled
+	SpanIDLabel = "span_id"
+	// ProfileIDAttribute is the span attribute Pyroscope and Grafana use to find the
+	// profile samples of a span
+	ProfileIDAttribute = "pyroscope.profile.id"
+)
+
+// profileKinds are captured every interval in addition to the CPU profile
+var profileKinds = []string{"heap", "goroutine", "mutex"}
+
+// cumulativeKinds count since the process started: the alloc_* values of heap and all
+// of mutex. They are exported as the change since the previous snapshot.
+var cumulativeKinds = map[string]bool{"heap": true, "mutex": true}
+
+// profiling is set while a Profiler runs so request spans are only labelled when the
+// labels end up somewhere
+var profiling atomic.Bool
+
+// Profile is one captured profile in gzipped pprof format
+type Profile struct {
+	Kind  string
+	Data  []byte
+	From  time.Time
+	Until time.Time
+}
+
+// ProfileExporter ships captured profiles
+type ProfileExporter interface {
+	Export(ctx context.Context, p Profile) error
+}
+
+// Profiler continuously captures CPU, heap, goroutine and mutex profiles and hands
+// them to an exporter once per interval
+type Profiler struct {
+	cfg      *config.ProfilingConfigs
+	exporter ProfileExporter
+	logger   *slog.Logger
+
+	// previous holds the last snapshot of each cumulative kind, see delta
+	previous map[string]*profile.Profile
+}
+
+// NewProfiler creates a Profiler with the exporter selected by PROFILING_EXPORTER;
+// profiles are tagged with the service name, version and environment
+func NewProfiler(cfg *config.ProfilingConfigs, server *config.ServerConfigs, logger *slog.Logger) (*Profiler, error) {
+	var exporter ProfileExporter
+	switch cfg.Exporter {
+	case "pyroscope":
+		exporter = newPyroscopeExporter(cfg, server.ServiceName, map[string]string{
+			"service_name": server.ServiceName,
+			"version":      Version(),
+			"environment":  server.Environment,
+		})
+	case "dir":
+		exporter = newDirExporter(cfg.Dir, server.ServiceName, cfg.MaxFiles)
+	default:
+		return nil, fmt.Errorf("unknown profile exporter %q", cfg.Exporter)
+	}
+
+	return &Profiler{cfg: cfg, exporter: exporter, logger: logger, previous: make(map[string]*profile.Profile)}, nil
+}
+
+// Run profiles until ctx is done. The CPU profiler is busy meanwhile, so
+// /system/debug/pprof/profile fails while it runs.
+func (p *Profiler) Run(ctx context.Context) {
+	p.logger.Info("profiler started", "exporter", p.cfg.Exporter, "interval", p.cfg.Interval)
+
+	runtime.SetMutexProfileFraction(p.cfg.MutexFraction)
+	defer runtime.SetMutexProfileFraction(0)
+	profiling.Store(true)
+	defer profiling.Store(false)
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	from := time.Now()
+	cpu := p.startCPU()
+	for {
+		done := false
+		select {
+		case <-ctx.Done():
+			done = true
+		case <-ticker.C:
+		}
+
+		until := time.Now()
+		profiles := p.snapshot(cpu, from, until)
+		if !done {
+			// Restart right away so the CPU windows don't leave gaps
+			cpu = p.startCPU()
+		}
+
+		exportCtx := ctx
+		if done {
+			// Flush the last window even though ctx is already done
+			var cancel context.CancelFunc
+			exportCtx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+		}
+		p.export(exportCtx, profiles)
+
+		if done {
+			p.logger.Info("profiler stopped")
+			return
+		}
+		from = until
+	}
+}
+
+// startCPU starts the CPU profiler into a buffer; nil when another CPU profile is running
+func (p *Profiler) startCPU() *bytes.Buffer {
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		p.logger.Warn("failed to start cpu profile", "error", err)
+		return nil
+	}
+	return &buf
+}
+
+// snapshot stops the running CPU profile and captures the other profiles
+func (p *Profiler) snapshot(cpu *bytes.Buffer, from, until time.Time) []Profile {
+	profiles := make([]Profile, 0, len(profileKinds)+1)
+	if cpu != nil {
+		pprof.StopCPUProfile()
+		profiles = append(profiles, Profile{Kind: "cpu", Data: cpu.Bytes(), From: from, Until: until})
+	}
+
+	for _, kind := range profileKinds {
+		var buf bytes.Buffer
+		if err := pprof.Lookup(kind).WriteTo(&buf, 0); err != nil {
+			p.logger.Warn("failed to capture profile", "kind", kind, "error", err)
+			continue
+		}
+		data := buf.Bytes()
+		if cumulativeKinds[kind] {
+			delta, err := p.delta(kind, data, from, until)
+			if err != nil {
+				p.logger.Warn("failed to compute profile delta", "kind", kind, "error", err)
+				continue
+			}
+			data = delta
+		}
+		profiles = append(profiles, Profile{Kind: kind, Data: data, From: from, Until: until})
+	}
+	return profiles
+}
+
+// delta subtracts the cumulative values of the previous snapshot of kind from data, so
+// they cover from..until only; in-use heap values are kept as they are. The first
+// snapshot covers everything since the process started.
+func (p *Profiler) delta(kind string, data []byte, from, until time.Time) ([]byte, error) {
+	current, err := profile.ParseData(data)
+	if err != nil {
+		return nil, err
+	}
+	previous := p.previous[kind]
+	p.previous[kind] = current
+	if previous == nil {
+		return data, nil
+	}
+
+	ratios := make([]float64, len(previous.SampleType))
+	for i, st := range previous.SampleType {
+		if kind == "mutex" || strings.HasPrefix(st.Type, "alloc_") {
+			ratios[i] = -1
+		}
+	}
+	negated := previous.Copy()
+	if err := negated.ScaleN(ratios); err != nil {
+		return nil, err
+	}
+	diff, err := profile.Merge([]*profile.Profile{current, negated})
+	if err != nil {
+		return nil, err
+	}
+	diff.TimeNanos = from.UnixNano()
+	diff.DurationNanos = until.Sub(from).Nanoseconds()
+
+	var buf bytes.Buffer
+	if err := diff.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *Profiler) export(ctx context.Context, profiles []Profile) {
+	for _, profile := range profiles {
+		if err := p.exporter.Export(ctx, profile); err != nil {
+			ProfilesExportedTotal.WithLabelValues(profile.Kind, "error").Inc()
+			p.logger.Warn("failed to export profile", "kind", profile.Kind, "error", err)
+			continue
+		}
+		ProfilesExportedTotal.WithLabelValues(profile.Kind, "success").Inc()
+	}
+}
+
+// WithSpanProfileLabels runs fn with the span ID of ctx as a pprof label and links the
+// span to its samples through the pyroscope.profile.id attribute. It is a plain call
+// when no Profiler runs or ctx has no recording span.
+func WithSpanProfileLabels(ctx context.Context, fn func(context.Context)) {
+	span := trace.SpanFromContext(ctx)
+	if !profiling.Load() || !span.IsRecording() {
+		fn(ctx)
+		return
+	}
+
+	id := span.SpanContext().SpanID().String()
+	span.SetAttributes(attribute.String(ProfileIDAttribute, id))
+	pprof.Do(ctx, pprof.Labels(SpanIDLabel, id), fn)
+}
@@ -0,0 +1,153 @@
+package meta
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go-chi-boilerplate/internal/config"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pyroscopeExporter pushes profiles to the /ingest API of Pyroscope in pprof format
+type pyroscopeExporter struct {
+	endpoint string
+	service  string
+	token    string
+	tenantID string
+	tags     string
+	client   *http.Client
+}
+
+func newPyroscopeExporter(cfg *config.ProfilingConfigs, service string, tags map[string]string) *pyroscopeExporter {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+tags[k])
+	}
+
+	return &pyroscopeExporter{
+		endpoint: strings.TrimSuffix(cfg.ServerURL, "/") + "/ingest",
+		service:  service,
+		token:    cfg.AuthToken,
+		tenantID: cfg.TenantID,
+		tags:     "{" + strings.Join(pairs, ",") + "}",
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Export uploads p as the "profile" part of a multipart form; the application name is
+// <service>.<kind> so every kind gets its own series on older Pyroscope servers
+func (e *pyroscopeExporter) Export(ctx context.Context, p Profile) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(p.Data); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("name", e.service+"."+p.Kind+e.tags)
+	query.Set("from", strconv.FormatInt(p.From.Unix(), 10))
+	query.Set("until", strconv.FormatInt(p.Until.Unix(), 10))
+	query.Set("spyName", "gospy")
+	if p.Kind == "cpu" {
+		query.Set("sampleRate", "100")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+"?"+query.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if e.token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+	if e.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", e.tenantID)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pyroscope responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// dirExporter writes profiles to <dir>/<service>-<kind>-<from>.pb.gz and keeps the
+// newest maxFiles files of each kind
+type dirExporter struct {
+	dir      string
+	service  string
+	maxFiles int
+}
+
+func newDirExporter(dir, service string, maxFiles int) *dirExporter {
+	return &dirExporter{dir: dir, service: service, maxFiles: maxFiles}
+}
+
+func (e *dirExporter) Export(ctx context.Context, p Profile) error {
+	if err := os.MkdirAll(e.dir, 0o755); err != nil {
+		return err
+	}
+
+	prefix := e.service + "-" + p.Kind + "-"
+	name := filepath.Join(e.dir, prefix+p.From.UTC().Format("20060102T150405Z")+".pb.gz")
+	if err := os.WriteFile(name, p.Data, 0o644); err != nil {
+		return err
+	}
+	return e.rotate(prefix)
+}
+
+// rotate removes the oldest files with prefix beyond maxFiles; the timestamp in the
+// name sorts chronologically
+func (e *dirExporter) rotate(prefix string) error {
+	entries, err := os.ReadDir(e.dir)
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) && strings.HasSuffix(entry.Name(), ".pb.gz") {
+			files = append(files, entry.Name())
+		}
+	}
+	if len(files) <= e.maxFiles {
+		return nil
+	}
+
+	slices.Sort(files)
+	for _, name := range files[:len(files)-e.maxFiles] {
+		if err := os.Remove(filepath.Join(e.dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id           BIGSERIAL PRIMARY KEY,
+    topic        TEXT        NOT NULL,
+    payload      JSONB       NOT NULL,
+    status       TEXT        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    last_error   TEXT,
+    available_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_messages_pending_idx
+    ON outbox_messages (available_at, id)
+    WHERE status = 'pending';
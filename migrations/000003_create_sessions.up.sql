@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id             TEXT        PRIMARY KEY,
+    data           BYTEA       NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL,
+    last_active_at TIMESTAMPTZ NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_expires_at_idx
+    ON sessions (expires_at);